@@ -7,6 +7,8 @@ if [ -z "$version" ]; then
   exit
 fi
 
+# Cross-compiling disables cgo, so these binaries can't load
+# native extensions (Go plugins).
 GOOS=darwin GOARCH=amd64 go build
 zip joker-$version-mac-amd64.zip joker
 GOOS=linux GOARCH=amd64 go build
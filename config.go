@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/candid82/joker/core"
+)
+
+func homeDir() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return home
+}
+
+// Reads .joker config file from user's home directory.
+// Returns nil if there is no config.
+func readConfig() Map {
+	f, err := os.Open(filepath.Join(homeDir(), ".joker"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	config, err := TryRead(NewReader(bufio.NewReader(f), f.Name()))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil
+	}
+	m, _ := config.(Map)
+	return m
+}
+
+// Loads native extensions listed under :native-extensions key
+// of .joker file. Relative paths are resolved against home directory.
+func loadNativeExtensions() {
+	config := readConfig()
+	if config == nil {
+		return
+	}
+	ok, exts := config.Get(MakeKeyword("native-extensions"))
+	if !ok {
+		return
+	}
+	seq, ok := exts.(Seqable)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Error: :native-extensions must be a vector of file names")
+		return
+	}
+	for s := seq.Seq(); !s.IsEmpty(); s = s.Rest() {
+		path, ok := s.First().(String)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "Error: :native-extensions must be a vector of file names")
+			continue
+		}
+		filename := path.S
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(homeDir(), filename)
+		}
+		if err := LoadNative(filename); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+		}
+	}
+}
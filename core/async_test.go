@@ -0,0 +1,63 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRequireAsync(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `
+(ns async-test
+  (:require [joker.async :as a :refer [go <! >!]]))
+
+(defn round-trip [ms]
+  (let [in (a/chan)
+        out (a/chan)]
+    (go (>! out (inc (<! in))))
+    (a/>!! in 1)
+    (a/alts!! [out (a/timeout ms)])))`)
+	t.Run("go blocks", func(t *testing.T) {
+		assertEval(t, in, `(let [[v ch] (round-trip 1000)] v)`, `2`)
+		assertEval(t, in, `(let [t (a/timeout 10) [v ch] (a/alts!! [(a/chan) t])] [v (= ch t)])`, `[nil true]`)
+	})
+	// joker.async and joker.core are built in, requiring them
+	// again doesn't reload anything.
+	t.Run("reload", func(t *testing.T) {
+		assertEval(t, in, `(do (require 'joker.async :reload) (contains? (loaded-libs) 'joker.async))`, `true`)
+		assertEval(t, in, `(do (require 'joker.core) :ok)`, `:ok`)
+		assertEval(t, in, `(let [[v ch] (round-trip 1000)] v)`, `2`)
+	})
+}
+
+func TestChannelArgs(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `(require '[joker.async :as a])`)
+	tests := []struct {
+		src string
+		msg string
+	}{
+		{`(a/chan -1)`, "Buffer size must be non-negative, got -1"},
+		{`(a/chan (a/buffer -1))`, "Buffer size must be non-negative, got -1"},
+		{`(a/chan (a/sliding-buffer 0))`, "Dropping and sliding buffers must have positive size"},
+		{`(a/pipeline 0 (a/chan) inc (a/chan))`, "Parallelism must be positive, got 0"},
+		{`(a/pipeline -1 (a/chan) inc (a/chan))`, "Parallelism must be positive, got -1"},
+	}
+	for _, test := range tests {
+		if _, err := in.EvalString(test.src); err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.msg, err)
+		}
+	}
+	assertEval(t, in, `(try (a/chan -1) (catch Error e :caught))`, `:caught`)
+	src := `
+(let [from (a/chan 3)
+      to (a/chan 3)]
+  (doseq [x [1 2 3]] (a/>!! from x))
+  (a/close! from)
+  (a/pipeline 1 to inc from)
+  (loop [res []]
+    (if-let [v (a/<!! to)]
+      (recur (conj res v))
+      res)))`
+	assertEval(t, in, src, `[2 3 4]`)
+}
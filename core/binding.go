@@ -0,0 +1,114 @@
+package core
+
+type (
+	// Thread-local value of a var. Frames pushed on top of the one
+	// that created it share it, so that var-set is visible
+	// until the frame that created the binding is popped.
+	binding struct {
+		value Object
+	}
+	// Frame of the dynamic binding stack. Each Runtime (goroutine)
+	// has its own stack. bindings holds the bindings established
+	// by this frame and all the frames below it.
+	BindingFrame struct {
+		bindings map[*Var]*binding
+		prev     *BindingFrame
+	}
+)
+
+func (rt *Runtime) binding(v *Var) *binding {
+	if rt.bindings == nil {
+		return nil
+	}
+	return rt.bindings.bindings[v]
+}
+
+// Pushes a new frame of thread-local bindings. m maps vars
+// to their new values.
+func (rt *Runtime) PushBindings(m Map) {
+	frame := &BindingFrame{
+		bindings: make(map[*Var]*binding),
+		prev:     rt.bindings,
+	}
+	if rt.bindings != nil {
+		for v, b := range rt.bindings.bindings {
+			frame.bindings[v] = b
+		}
+	}
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		v := AssertVar(p.key, "Binding key must be a Var, got "+p.key.ToString(true))
+		if !v.isDynamic() {
+			panic(rt.NewError("Can't dynamically bind non-dynamic var: " + v.ToString(false)))
+		}
+		validate(v.validator, p.value)
+		v.threadBound = true
+		frame.bindings[v] = &binding{value: p.value}
+	}
+	rt.bindings = frame
+}
+
+func (rt *Runtime) PopBindings() {
+	if rt.bindings == nil {
+		panic(rt.NewError("Pop without matching push"))
+	}
+	rt.bindings = rt.bindings.prev
+}
+
+// Returns a copy of the runtime for a new goroutine. Thread-local
+// bindings are conveyed by value: the goroutine starts with their
+// current values, but var-set in it doesn't affect the bindings
+// of the goroutine that started it, and vice versa.
+func (rt *Runtime) fork() *Runtime {
+	res := rt.clone()
+	if rt.bindings != nil {
+		frame := &BindingFrame{bindings: make(map[*Var]*binding, len(rt.bindings.bindings))}
+		for v, b := range rt.bindings.bindings {
+			frame.bindings[v] = &binding{value: b.value}
+		}
+		res.bindings = frame
+	}
+	return res
+}
+
+// Returns a map of all vars that are currently thread-bound
+// to their values.
+func (rt *Runtime) GetBindings() Map {
+	var res Map = EmptyArrayMap()
+	if rt.bindings != nil {
+		for v, b := range rt.bindings.bindings {
+			res = res.Assoc(v, b.value).(Map)
+		}
+	}
+	return res
+}
+
+func (v *Var) isDynamic() bool {
+	if v.meta == nil {
+		return false
+	}
+	ok, dynamic := v.meta.Get(MakeKeyword("dynamic"))
+	return ok && toBool(dynamic)
+}
+
+// Returns the current value of v: the thread-local one if v
+// is bound in the current runtime, the root one otherwise
+// (nil if v is unbound).
+func (v *Var) get() Object {
+	if v.threadBound {
+		if b := RT.binding(v); b != nil {
+			return b.value
+		}
+	}
+	return v.Value
+}
+
+// Sets the current value of v, like var-set does.
+func (v *Var) set(value Object) {
+	if b := RT.binding(v); b != nil {
+		validate(v.validator, value)
+		b.value = value
+	} else {
+		v.BindRoot(value)
+	}
+}
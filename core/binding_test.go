@@ -0,0 +1,49 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func newBindingInterpreter(t *testing.T) *Interpreter {
+	in := NewInterpreter()
+	evalString(t, in, `
+(def ^:dynamic *x* 1)
+(defn get-x [] *x*)`)
+	return in
+}
+
+func TestBindingScope(t *testing.T) {
+	in := newBindingInterpreter(t)
+	assertEval(t, in, `(binding [*x* 2] (get-x))`, `2`)
+	assertEval(t, in, `(binding [*x* 2] (binding [*x* 3] (get-x)))`, `3`)
+	assertEval(t, in, `(binding [*x* 2] (var-set #'*x* 3) (get-x))`, `3`)
+	assertEval(t, in, `(binding [*x* 2] (thread-bound? #'*x*))`, `true`)
+	// Bindings are popped when binding exits, normally or by an exception.
+	assertEval(t, in, `(do (binding [*x* 2] (var-set #'*x* 3)) *x*)`, `1`)
+	assertEval(t, in, `(do (try (binding [*x* 2] (throw (ex-info "boom" {}))) (catch Error e)) *x*)`, `1`)
+	assertEval(t, in, `(binding [*x* 2] (try (binding [*x* 3] (throw (ex-info "boom" {}))) (catch Error e *x*)))`, `2`)
+	assertEval(t, in, `(thread-bound? #'*x*)`, `false`)
+}
+
+func TestBindingConveyance(t *testing.T) {
+	in := newBindingInterpreter(t)
+	assertEval(t, in, `(binding [*x* 2] @(future (get-x)))`, `2`)
+	assertEval(t, in, `(binding [*x* 2] @(future @(future (get-x))))`, `2`)
+	assertEval(t, in, `(let [f (binding [*x* 2] (bound-fn [] (get-x)))] (f))`, `2`)
+	assertEval(t, in, `(let [f (binding [*x* 2] (bound-fn* get-x))] @(future (f)))`, `2`)
+	// Plain fns don't capture bindings.
+	assertEval(t, in, `(let [f (binding [*x* 2] (fn [] (get-x)))] (f))`, `1`)
+}
+
+func TestBindingNonDynamicVar(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `(def y 1)`)
+	_, err := in.EvalString(`(binding [y 2] y)`)
+	if err == nil || !strings.Contains(err.Error(), "Can't dynamically bind non-dynamic var: #'user/y") {
+		t.Errorf("expected error, got %v", err)
+	}
+	assertEval(t, in, `y`, `1`)
+	// Core vars like *print-readably* are dynamic.
+	assertEval(t, in, `(with-out-str (binding [*print-readably* false] (pr "a")))`, `"a"`)
+}
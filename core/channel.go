@@ -0,0 +1,309 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+type (
+	BufferPolicy int
+	// Channel is a Go channel of Joker objects that can be closed
+	// while goroutines are blocked sending to it. Buffered values
+	// can still be taken from a closed channel.
+	Channel struct {
+		ch        chan Object
+		closed    chan struct{}
+		closeOnce sync.Once
+		policy    BufferPolicy
+	}
+)
+
+const (
+	// Puts block when the buffer is full.
+	FIXED_BUFFER BufferPolicy = iota
+	// Puts to full buffer are dropped.
+	DROPPING_BUFFER
+	// Puts to full buffer drop the oldest buffered value.
+	SLIDING_BUFFER
+)
+
+func MakeChannel(size int, policy BufferPolicy) *Channel {
+	if size < 0 {
+		panic(RT.NewError(fmt.Sprintf("Buffer size must be non-negative, got %d", size)))
+	}
+	if policy != FIXED_BUFFER && size < 1 {
+		panic(RT.NewError("Dropping and sliding buffers must have positive size"))
+	}
+	return &Channel{
+		ch:     make(chan Object, size),
+		closed: make(chan struct{}),
+		policy: policy,
+	}
+}
+
+func (c *Channel) ToString(escape bool) string {
+	return "#object[Channel]"
+}
+
+func (c *Channel) Equals(other interface{}) bool {
+	return c == other
+}
+
+func (c *Channel) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (c *Channel) GetType() *Type {
+	return TYPES["Channel"]
+}
+
+func (c *Channel) Hash() uint32 {
+	return hashPtr(uintptr(unsafe.Pointer(c)))
+}
+
+func (c *Channel) WithInfo(info *ObjectInfo) Object {
+	return c
+}
+
+func (c *Channel) IsClosed() bool {
+	return isDone(c.closed)
+}
+
+// Closes the channel. Pending and future puts return false,
+// takes return buffered values and then nil. Can be called
+// from any goroutine.
+func (c *Channel) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+func checkPutValue(v Object) {
+	if v.Equals(NIL) {
+		panic(RT.NewError("Can't put nil on channel"))
+	}
+}
+
+// Puts v without blocking if possible. Returns (result, true)
+// if the put completed, where result is false if the channel
+// is closed.
+func (c *Channel) offer(v Object) (bool, bool) {
+	if c.IsClosed() {
+		return false, true
+	}
+	switch c.policy {
+	case DROPPING_BUFFER:
+		select {
+		case c.ch <- v:
+		default:
+		}
+		return true, true
+	case SLIDING_BUFFER:
+		for {
+			select {
+			case c.ch <- v:
+				return true, true
+			default:
+			}
+			select {
+			case <-c.ch:
+			default:
+			}
+		}
+	}
+	select {
+	case c.ch <- v:
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// Takes a value without blocking if possible. Returns (value, true)
+// if the take completed, where value is nil if the channel
+// is closed and empty.
+func (c *Channel) poll() (Object, bool) {
+	select {
+	case v := <-c.ch:
+		return v, true
+	default:
+	}
+	if c.IsClosed() {
+		return c.drain(), true
+	}
+	return nil, false
+}
+
+func (c *Channel) drain() Object {
+	select {
+	case v := <-c.ch:
+		return v
+	default:
+		return NIL
+	}
+}
+
+// Puts v to the channel, blocking until there is room for it.
+// Returns false if the channel is closed.
+func (c *Channel) Put(v Object) bool {
+	checkPutValue(v)
+	if res, ok := c.offer(v); ok {
+		return res
+	}
+	res := true
+	RunUnlocked(func() {
+		select {
+		case c.ch <- v:
+		case <-c.closed:
+			res = false
+		}
+	})
+	return res
+}
+
+// Takes a value from the channel, blocking until one is available.
+// Returns nil if the channel is closed and empty.
+func (c *Channel) Take() Object {
+	if v, ok := c.poll(); ok {
+		return v
+	}
+	var res Object
+	RunUnlocked(func() {
+		select {
+		case res = <-c.ch:
+		case <-c.closed:
+			res = c.drain()
+		}
+	})
+	return res
+}
+
+// Returns a channel that closes after d.
+func MakeTimeoutChannel(d time.Duration) *Channel {
+	res := MakeChannel(0, FIXED_BUFFER)
+	time.AfterFunc(d, res.Close)
+	return res
+}
+
+// Runs fn in a new goroutine. The returned channel receives
+// the result of fn (unless it's nil) and then closes.
+func ChannelCall(fn Callable) *Channel {
+	res := MakeChannel(1, FIXED_BUFFER)
+	goJoker(func() {
+		defer res.Close()
+		defer func() {
+			if r := recover(); r != nil {
+				if err, ok := r.(error); ok {
+					PrintError(os.Stderr, err)
+					return
+				}
+				panic(r)
+			}
+		}()
+		if v := fn.Call([]Object{}); !v.Equals(NIL) {
+			res.Put(v)
+		}
+	})
+	return res
+}
+
+type altsOp struct {
+	ch  *Channel
+	val Object // nil for takes
+}
+
+func toAltsOp(port Object) altsOp {
+	switch port := port.(type) {
+	case *Channel:
+		return altsOp{ch: port}
+	case *Vector:
+		if port.Count() == 2 {
+			if ch, ok := port.at(0).(*Channel); ok {
+				checkPutValue(port.at(1))
+				return altsOp{ch: ch, val: port.at(1)}
+			}
+		}
+	}
+	panic(RT.NewError("alts!! port must be a channel or a [channel value] vector, got " + port.ToString(true)))
+}
+
+func (op altsOp) result(v Object) Object {
+	return NewVectorFrom(v, op.ch)
+}
+
+func (op altsOp) try() (Object, bool) {
+	if op.val == nil {
+		if v, ok := op.ch.poll(); ok {
+			return op.result(v), true
+		}
+		return nil, false
+	}
+	if res, ok := op.ch.offer(op.val); ok {
+		return op.result(Bool{B: res}), true
+	}
+	return nil, false
+}
+
+// Completes at most one of the operations on ports, where each port
+// is either a channel to take from or a [channel value] vector
+// to put to. Returns [value port] for takes and [true-or-false port]
+// for puts. If none of the operations can complete immediately and
+// hasDefault is true, returns [defaultValue :default]. Otherwise
+// blocks until one of them completes. Ready operations are chosen
+// at random unless priority is true, in which case
+// the first one wins.
+func Alts(ports []Object, hasDefault bool, defaultValue Object, priority bool) Object {
+	if len(ports) == 0 {
+		panic(RT.NewError("alts!! requires at least one port"))
+	}
+	ops := make([]altsOp, len(ports))
+	for i, port := range ports {
+		ops[i] = toAltsOp(port)
+	}
+	order := rand.Perm(len(ops))
+	if priority {
+		for i := range order {
+			order[i] = i
+		}
+	}
+	for _, i := range order {
+		if res, ok := ops[i].try(); ok {
+			return res
+		}
+	}
+	if hasDefault {
+		return NewVectorFrom(defaultValue, MakeKeyword("default"))
+	}
+	// Every operation gets two cases: the operation itself
+	// and waiting for its channel to close.
+	cases := make([]reflect.SelectCase, 0, len(ops)*2)
+	for _, op := range ops {
+		if op.val == nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(op.ch.ch)})
+		} else {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(op.ch.ch), Send: reflect.ValueOf(&op.val).Elem()})
+		}
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(op.ch.closed)})
+	}
+	var chosen int
+	var recv reflect.Value
+	RunUnlocked(func() {
+		chosen, recv, _ = reflect.Select(cases)
+	})
+	op := ops[chosen/2]
+	switch {
+	case chosen%2 == 1 && op.val == nil:
+		return op.result(op.ch.drain())
+	case chosen%2 == 1:
+		return op.result(Bool{B: false})
+	case op.val == nil:
+		return op.result(recv.Interface().(Object))
+	default:
+		return op.result(Bool{B: true})
+	}
+}
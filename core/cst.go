@@ -0,0 +1,449 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// Lossless concrete syntax tree reader. Unlike Read, it keeps
+// whitespace, comments, discarded forms and the original spelling
+// of every token, so that writing the tree out produces the exact
+// source it was read from. Intended for tools that need to rewrite
+// source code (formatters, refactoring tools, etc.).
+
+type (
+	SyntaxKind int
+	// Leaf nodes (whitespace, comments, tokens) keep their source text
+	// in Text. Other nodes keep the text that opens them ("(", "#{", "'",
+	// "#?@(", "#:foo{", etc.) in Text, closing delimiter (if any) in Close
+	// and everything in between, including whitespace and comments, in Children.
+	SyntaxNode struct {
+		Position
+		Kind     SyntaxKind
+		Text     string
+		Close    string
+		Children []*SyntaxNode
+	}
+)
+
+const (
+	SyntaxFile SyntaxKind = iota
+	SyntaxWhitespace
+	SyntaxComment
+	SyntaxSymbol
+	SyntaxKeyword
+	SyntaxNumber
+	SyntaxString
+	SyntaxRegex
+	SyntaxChar
+	SyntaxSymbolicValue
+	SyntaxList
+	SyntaxVector
+	SyntaxMap
+	SyntaxSet
+	SyntaxFn
+	SyntaxConditional
+	SyntaxNamespacedMap
+	SyntaxQuote
+	SyntaxBackquote
+	SyntaxUnquote
+	SyntaxUnquoteSplicing
+	SyntaxDeref
+	SyntaxVar
+	SyntaxMeta
+	SyntaxDiscard
+	SyntaxTagged
+)
+
+var nodeKindNames = [...]string{
+	SyntaxFile:            "file",
+	SyntaxWhitespace:      "whitespace",
+	SyntaxComment:         "comment",
+	SyntaxSymbol:          "symbol",
+	SyntaxKeyword:         "keyword",
+	SyntaxNumber:          "number",
+	SyntaxString:          "string",
+	SyntaxRegex:           "regex",
+	SyntaxChar:            "char",
+	SyntaxSymbolicValue:   "symbolic value",
+	SyntaxList:            "list",
+	SyntaxVector:          "vector",
+	SyntaxMap:             "map",
+	SyntaxSet:             "set",
+	SyntaxFn:              "fn",
+	SyntaxConditional:     "reader conditional",
+	SyntaxNamespacedMap:   "namespaced map",
+	SyntaxQuote:           "quote",
+	SyntaxBackquote:       "syntax quote",
+	SyntaxUnquote:         "unquote",
+	SyntaxUnquoteSplicing: "unquote splicing",
+	SyntaxDeref:           "deref",
+	SyntaxVar:             "var",
+	SyntaxMeta:            "meta",
+	SyntaxDiscard:         "discard",
+	SyntaxTagged:          "tagged literal",
+}
+
+func (k SyntaxKind) String() string {
+	return nodeKindNames[k]
+}
+
+// Returns true for nodes that don't represent a form:
+// whitespace, comments and discarded forms.
+func (n *SyntaxNode) IsTrivia() bool {
+	switch n.Kind {
+	case SyntaxWhitespace, SyntaxComment, SyntaxDiscard:
+		return true
+	}
+	return false
+}
+
+// Returns the children of n that are forms (i.e. not trivia).
+func (n *SyntaxNode) Forms() []*SyntaxNode {
+	var res []*SyntaxNode
+	for _, child := range n.Children {
+		if !child.IsTrivia() {
+			res = append(res, child)
+		}
+	}
+	return res
+}
+
+func (n *SyntaxNode) WriteTo(w io.Writer) (int64, error) {
+	var total int64
+	c, err := io.WriteString(w, n.Text)
+	total += int64(c)
+	if err != nil {
+		return total, err
+	}
+	for _, child := range n.Children {
+		c, err := child.WriteTo(w)
+		total += c
+		if err != nil {
+			return total, err
+		}
+	}
+	c, err = io.WriteString(w, n.Close)
+	total += int64(c)
+	return total, err
+}
+
+func (n *SyntaxNode) String() string {
+	var b bytes.Buffer
+	n.WriteTo(&b)
+	return b.String()
+}
+
+type cstReader struct {
+	reader *Reader
+	b      bytes.Buffer
+}
+
+func (cr *cstReader) get() rune {
+	r := cr.reader.Get()
+	if r != EOF {
+		cr.b.WriteRune(r)
+	}
+	return r
+}
+
+func (cr *cstReader) unget() {
+	if cr.reader.isEof {
+		return
+	}
+	cr.reader.Unget()
+	cr.b.Truncate(cr.b.Len() - utf8RuneLen(cr.b.Bytes()))
+}
+
+func utf8RuneLen(b []byte) int {
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i]&0xC0 != 0x80 {
+			return len(b) - i
+		}
+	}
+	return len(b)
+}
+
+// Returns the position of the last consumed rune. Unlike reader's
+// position, it points to the end of the line for newlines.
+func (cr *cstReader) position() (line int, column int) {
+	if cr.reader.column == 0 && cr.reader.line > 1 {
+		return cr.reader.line - 1, cr.reader.prevLineLength + 1
+	}
+	return cr.reader.line, cr.reader.column
+}
+
+// Starts a new node at the position of the last consumed rune.
+func (cr *cstReader) startNode(kind SyntaxKind) *SyntaxNode {
+	line, column := cr.position()
+	return &SyntaxNode{
+		Kind: kind,
+		Position: Position{
+			startLine:   line,
+			startColumn: column,
+			filename:    cr.reader.filename,
+		},
+	}
+}
+
+func (cr *cstReader) takeText() string {
+	res := cr.b.String()
+	cr.b.Reset()
+	return res
+}
+
+func (cr *cstReader) endNode(n *SyntaxNode) *SyntaxNode {
+	n.endLine, n.endColumn = cr.position()
+	return n
+}
+
+func (cr *cstReader) error(msg string) ReadError {
+	return MakeReadError(cr.reader, msg)
+}
+
+func isCSTWhitespace(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// Reads the rest of whitespace (or comment if isComment is true).
+// The first rune has already been consumed. Comments end
+// before the end of line.
+func (cr *cstReader) readTrivia(n *SyntaxNode, isComment bool) *SyntaxNode {
+	for r := cr.get(); r != EOF; r = cr.get() {
+		if isComment && r == '\n' || !isComment && !isCSTWhitespace(r) {
+			cr.unget()
+			break
+		}
+	}
+	n.Text = cr.takeText()
+	return cr.endNode(n)
+}
+
+// Reads a string (or regex) literal. The opening quote has already been consumed.
+func (cr *cstReader) readString(n *SyntaxNode) *SyntaxNode {
+	for r := cr.get(); r != '"'; r = cr.get() {
+		switch r {
+		case '\\':
+			if cr.get() == EOF {
+				panic(cr.error("Unexpected end of file"))
+			}
+		case EOF:
+			panic(cr.error("Unexpected end of file"))
+		}
+	}
+	n.Text = cr.takeText()
+	return cr.endNode(n)
+}
+
+// Reads a character literal. The backslash has already been consumed.
+func (cr *cstReader) readChar(n *SyntaxNode) *SyntaxNode {
+	if cr.get() == EOF {
+		panic(cr.error("Incomplete character literal"))
+	}
+	for r := cr.get(); !isDelimiter(r); r = cr.get() {
+	}
+	cr.unget()
+	n.Text = cr.takeText()
+	return cr.endNode(n)
+}
+
+// Reads children up to the closing delimiter.
+func (cr *cstReader) readCollection(n *SyntaxNode, closing rune) *SyntaxNode {
+	n.Text = cr.takeText()
+	for {
+		child := cr.readNode()
+		if child == nil {
+			r := cr.get()
+			if r == closing {
+				cr.takeText()
+				n.Close = string(closing)
+				return cr.endNode(n)
+			}
+			if r == EOF {
+				panic(cr.error("Unexpected end of file"))
+			}
+			panic(cr.error(fmt.Sprintf("Unexpected %c", r)))
+		}
+		n.Children = append(n.Children, child)
+	}
+}
+
+// Reads trivia followed by count forms and adds all of them as children of n.
+func (cr *cstReader) readPrefixed(n *SyntaxNode, count int) *SyntaxNode {
+	n.Text = cr.takeText()
+	for count > 0 {
+		child := cr.readNode()
+		if child == nil {
+			if cr.get() == EOF {
+				panic(cr.error("Unexpected end of file"))
+			}
+			panic(cr.error(fmt.Sprintf("Unexpected %s", cr.takeText())))
+		}
+		n.Children = append(n.Children, child)
+		if !child.IsTrivia() {
+			count--
+		}
+	}
+	return cr.endNode(n)
+}
+
+func (cr *cstReader) readDispatch(n *SyntaxNode) *SyntaxNode {
+	r := cr.get()
+	switch r {
+	case '{':
+		n.Kind = SyntaxSet
+		return cr.readCollection(n, '}')
+	case '(':
+		n.Kind = SyntaxFn
+		return cr.readCollection(n, ')')
+	case '"':
+		n.Kind = SyntaxRegex
+		return cr.readString(n)
+	case '\'':
+		n.Kind = SyntaxVar
+		return cr.readPrefixed(n, 1)
+	case '_':
+		n.Kind = SyntaxDiscard
+		return cr.readPrefixed(n, 1)
+	case '^':
+		n.Kind = SyntaxMeta
+		return cr.readPrefixed(n, 2)
+	case '!':
+		n.Kind = SyntaxComment
+		return cr.readTrivia(n, true)
+	case '#':
+		n.Kind = SyntaxSymbolicValue
+		for r := cr.get(); !isDelimiter(r); r = cr.get() {
+		}
+		cr.unget()
+		n.Text = cr.takeText()
+		return cr.endNode(n)
+	case '?':
+		n.Kind = SyntaxConditional
+		if cr.get() != '@' {
+			cr.unget()
+		}
+		for r := cr.get(); r != '('; r = cr.get() {
+			if !isCSTWhitespace(r) {
+				panic(cr.error("Reader conditional body must be a list"))
+			}
+		}
+		return cr.readCollection(n, ')')
+	case ':':
+		n.Kind = SyntaxNamespacedMap
+		for r := cr.get(); r != '{'; r = cr.get() {
+			if r == EOF || (isDelimiter(r) && !isCSTWhitespace(r)) {
+				panic(cr.error("Namespaced map must specify a map"))
+			}
+		}
+		return cr.readCollection(n, '}')
+	case EOF:
+		panic(cr.error("Unexpected end of file"))
+	}
+	cr.unget()
+	n.Kind = SyntaxTagged
+	return cr.readPrefixed(n, 2)
+}
+
+// Reads the next node. Returns nil if the next rune is a closing
+// delimiter or EOF, leaving it unread.
+func (cr *cstReader) readNode() *SyntaxNode {
+	r := cr.get()
+	if r == ')' || r == ']' || r == '}' || r == EOF {
+		cr.unget()
+		cr.takeText()
+		return nil
+	}
+	n := cr.startNode(SyntaxSymbol)
+	switch {
+	case isCSTWhitespace(r):
+		n.Kind = SyntaxWhitespace
+		return cr.readTrivia(n, false)
+	case r == ';':
+		n.Kind = SyntaxComment
+		return cr.readTrivia(n, true)
+	case r == '"':
+		n.Kind = SyntaxString
+		return cr.readString(n)
+	case r == '\\':
+		n.Kind = SyntaxChar
+		return cr.readChar(n)
+	case r == '(':
+		n.Kind = SyntaxList
+		return cr.readCollection(n, ')')
+	case r == '[':
+		n.Kind = SyntaxVector
+		return cr.readCollection(n, ']')
+	case r == '{':
+		n.Kind = SyntaxMap
+		return cr.readCollection(n, '}')
+	case r == '\'':
+		n.Kind = SyntaxQuote
+		return cr.readPrefixed(n, 1)
+	case r == '`':
+		n.Kind = SyntaxBackquote
+		return cr.readPrefixed(n, 1)
+	case r == '@':
+		n.Kind = SyntaxDeref
+		return cr.readPrefixed(n, 1)
+	case r == '~':
+		n.Kind = SyntaxUnquote
+		if cr.get() == '@' {
+			n.Kind = SyntaxUnquoteSplicing
+		} else {
+			cr.unget()
+		}
+		return cr.readPrefixed(n, 1)
+	case r == '^':
+		n.Kind = SyntaxMeta
+		return cr.readPrefixed(n, 2)
+	case r == '#':
+		return cr.readDispatch(n)
+	case r == ':':
+		n.Kind = SyntaxKeyword
+	case unicode.IsDigit(r):
+		n.Kind = SyntaxNumber
+	case r == '+' || r == '-':
+		next := cr.get()
+		cr.unget()
+		if unicode.IsDigit(next) {
+			n.Kind = SyntaxNumber
+		}
+	}
+	for r := cr.get(); !isDelimiter(r); r = cr.get() {
+	}
+	cr.unget()
+	n.Text = cr.takeText()
+	return cr.endNode(n)
+}
+
+// Reads everything from reader into a SyntaxFile node. Writing the
+// resulting tree out reproduces the input exactly. Node positions
+// follow the same conventions as positions of objects returned by Read.
+func ReadCST(reader *Reader) (res *SyntaxNode, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case ReadError:
+				res, err = nil, r
+			default:
+				panic(r)
+			}
+		}
+	}()
+	cr := &cstReader{reader: reader}
+	res = cr.startNode(SyntaxFile)
+	for {
+		child := cr.readNode()
+		if child == nil {
+			r := cr.get()
+			if r == EOF {
+				return cr.endNode(res), nil
+			}
+			panic(cr.error(fmt.Sprintf("Unexpected %c", r)))
+		}
+		res.Children = append(res.Children, child)
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func readCSTString(t *testing.T, src string) *SyntaxNode {
+	res, err := ReadCST(NewReader(bufio.NewReader(strings.NewReader(src)), "<test>"))
+	if err != nil {
+		t.Fatalf("%q: %v", src, err)
+	}
+	return res
+}
+
+func TestCSTRoundTrip(t *testing.T) {
+	sources := []string{
+		"",
+		"  \n\n",
+		"(def x 1) ; trailing comment\n",
+		";; comment\n(defn f\n  \"doc\"\n  [x] ; arg\n  (inc x))\n",
+		"(ns foo)\n;; no newline at the end",
+		"[1 #_2 3 #_ #_ 4 5]",
+		"#_(unbalanced? \"no\")\n(foo)",
+		"#?(:clj 1 :cljs 2)",
+		"#?@(:clj [1 2]\n    :cljs [3])",
+		"#? (:clj 1)",
+		"[\\a \\space \\newline \\u00e9 \\o101 \\( \\) \\; \\  \\\n \\\t]",
+		"\"string with \\\" and \\n\nnewline\"",
+		"#\"regex\\d+\" ##Inf ##-Inf ##NaN",
+		"#{1 2} #:foo{:a 1} #::{:b 2} #:: bar{:c 3}",
+		"'x `(a ~b ~@c) @d #'e #(+ % %2)",
+		"^:private ^{:doc \"d\"} ^String x",
+		"#inst \"2017-01-01\" #uuid \"00000000-0000-0000-0000-000000000000\"",
+		"1, 2,,3\r\n4.5 1/2 0x1F 1N 1.5M :k ::k :a/b nil true false",
+		"(привет \"мир\") ; ünïcode",
+	}
+	for _, src := range sources {
+		if res := readCSTString(t, src).String(); res != src {
+			t.Errorf("expected %q, got %q", src, res)
+		}
+	}
+}
+
+func TestCSTNodeKinds(t *testing.T) {
+	tree := readCSTString(t, "#_x #?(:clj 1) \\space ; c\n")
+	var kinds []SyntaxKind
+	for _, child := range tree.Children {
+		kinds = append(kinds, child.Kind)
+	}
+	expected := []SyntaxKind{
+		SyntaxDiscard, SyntaxWhitespace, SyntaxConditional, SyntaxWhitespace,
+		SyntaxChar, SyntaxWhitespace, SyntaxComment, SyntaxWhitespace,
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, kinds)
+	}
+	for i := range kinds {
+		if kinds[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, kinds)
+		}
+	}
+	if forms := tree.Forms(); len(forms) != 2 {
+		t.Errorf("expected 2 forms, got %d", len(forms))
+	}
+}
+
+func TestCSTErrors(t *testing.T) {
+	for _, src := range []string{"(foo", "[1 2)", ")", "\"abc", "#_"} {
+		if _, err := ReadCST(NewReader(bufio.NewReader(strings.NewReader(src)), "<test>")); err == nil {
+			t.Errorf("%q: expected read error", src)
+		}
+	}
+}
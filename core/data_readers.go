@@ -0,0 +1,74 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var dataReadersFiles = []string{"data_readers.joke", "data_readers.cljc"}
+
+func loadDataReadersFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	obj, err := TryRead(NewReader(bufio.NewReader(f), filename))
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	m, ok := obj.(Map)
+	if !ok {
+		return fmt.Errorf("%s: data readers file must contain a map", filename)
+	}
+	readers := AssertMap(GLOBAL_ENV.dataReaders.get(), "")
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		if _, ok := p.key.(Symbol); !ok {
+			return fmt.Errorf("%s: data reader tag must be a symbol, got %s", filename, p.key.ToString(true))
+		}
+		if _, ok := p.value.(Symbol); !ok {
+			return fmt.Errorf("%s: data reader function must be a symbol, got %s", filename, p.value.ToString(true))
+		}
+		readers = readers.Assoc(p.key, p.value).(Map)
+	}
+	GLOBAL_ENV.dataReaders.set(readers)
+	return nil
+}
+
+// Looks for data readers files (data_readers.joke and data_readers.cljc)
+// in dir and then in its parent directories, stopping at the first
+// directory that has any, and adds the readers declared in them
+// to *data-readers*. Reader functions are declared as symbols
+// and resolved when the tag is read, so their namespaces
+// have to be loaded by then.
+func LoadDataReaders(dir string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+	for {
+		found := false
+		for _, name := range dataReadersFiles {
+			filename := filepath.Join(dir, name)
+			if _, err := os.Stat(filename); err != nil {
+				continue
+			}
+			found = true
+			if err := loadDataReadersFile(filename); err != nil {
+				fmt.Fprintln(GLOBAL_ENV.errWriter(), err)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if found || parent == dir {
+			return
+		}
+		dir = parent
+	}
+}
@@ -1,25 +1,33 @@
 package core
 
 import (
+	"io"
 	"os"
 	"strings"
 )
 
 type (
 	Env struct {
-		Namespaces    map[*string]*Namespace
-		CoreNamespace *Namespace
-		stdout        *Var
-		stdin         *Var
-		stderr        *Var
-		printReadably *Var
-		file          *Var
-		args          *Var
-		ns            *Var
-		Features      Set
+		Namespaces          map[*string]*Namespace
+		CoreNamespace       *Namespace
+		stdout              *Var
+		stdin               *Var
+		stderr              *Var
+		printReadably       *Var
+		printNamespaceMaps  *Var
+		file                *Var
+		args                *Var
+		ns                  *Var
+		dataReaders         *Var
+		defaultDataReaderFn *Var
+		Features            Set
+		// Types defined with deftype and defrecord.
+		types map[string]*Type
 	}
 )
 
+var dynamicMeta Map = EmptyArrayMap().Assoc(MakeKeyword("dynamic"), Bool{B: true}).(Map)
+
 func NewEnv(currentNs Symbol, stdout *os.File, stdin *os.File, stderr *os.File) *Env {
 	features := EmptySet()
 	features.Add(MakeKeyword("default"))
@@ -27,19 +35,20 @@ func NewEnv(currentNs Symbol, stdout *os.File, stdin *os.File, stderr *os.File)
 	res := &Env{
 		Namespaces: make(map[*string]*Namespace),
 		Features:   features,
+		types:      make(map[string]*Type),
 	}
 	res.CoreNamespace = res.EnsureNamespace(MakeSymbol("joker.core"))
 	res.CoreNamespace.meta = MakeMeta(nil, "Core library of Joker.", "1.0")
-	res.ns = res.CoreNamespace.Intern(MakeSymbol("*ns*"))
+	res.ns = res.internDynamic("*ns*")
 	res.ns.Value = res.EnsureNamespace(currentNs)
-	res.stdout = res.CoreNamespace.Intern(MakeSymbol("*out*"))
+	res.stdout = res.internDynamic("*out*")
 	res.stdout.Value = &File{stdout}
-	res.stdin = res.CoreNamespace.Intern(MakeSymbol("*in*"))
+	res.stdin = res.internDynamic("*in*")
 	res.stdin.Value = &File{stdin}
-	res.stderr = res.CoreNamespace.Intern(MakeSymbol("*err*"))
+	res.stderr = res.internDynamic("*err*")
 	res.stderr.Value = &File{stderr}
-	res.file = res.CoreNamespace.Intern(MakeSymbol("*file*"))
-	res.args = res.CoreNamespace.Intern(MakeSymbol("*command-line-args*"))
+	res.file = res.internDynamic("*file*")
+	res.args = res.internDynamic("*command-line-args*")
 	args := EmptyVector
 	for _, arg := range os.Args[1:] {
 		args = args.Conjoin(String{S: arg})
@@ -49,14 +58,81 @@ func NewEnv(currentNs Symbol, stdout *os.File, stdin *os.File, stderr *os.File)
 	} else {
 		res.args.Value = NIL
 	}
-	res.printReadably = res.CoreNamespace.Intern(MakeSymbol("*print-readably*"))
+	res.printReadably = res.internDynamic("*print-readably*")
 	res.printReadably.Value = Bool{B: true}
-	res.CoreNamespace.Intern(MakeSymbol("*linter-mode*")).Value = Bool{B: LINTER_MODE}
+	res.printNamespaceMaps = res.internDynamic("*print-namespace-maps*")
+	res.printNamespaceMaps.Value = Bool{B: false}
+	res.dataReaders = res.internDynamic("*data-readers*")
+	res.dataReaders.Value = EmptyArrayMap()
+	res.defaultDataReaderFn = res.internDynamic("*default-data-reader-fn*")
+	res.defaultDataReaderFn.Value = NIL
+	res.internDynamic("*linter-mode*").Value = Bool{B: LINTER_MODE}
 	return res
 }
 
+// Vars that the environment creates are dynamic,
+// so that they can be rebound with binding.
+func (env *Env) internDynamic(name string) *Var {
+	vr := env.CoreNamespace.Intern(MakeSymbol(name))
+	vr.meta = dynamicMeta
+	return vr
+}
+
+// Returns a copy of env with copies of all its namespaces
+// but the core one, which is shared: the copies have their own
+// mappings, aliases and vars, so definitions made in them
+// don't affect env. Core vars that get changed (*ns*, *file*, etc.)
+// have to be thread-bound by whoever uses the copy.
+func (env *Env) clone() *Env {
+	res := *env
+	res.Namespaces = make(map[*string]*Namespace, len(env.Namespaces))
+	res.types = make(map[string]*Type, len(env.types))
+	for name, t := range env.types {
+		res.types[name] = t
+	}
+	vars := make(map[*Var]*Var)
+	for name, ns := range env.Namespaces {
+		if ns == env.CoreNamespace {
+			res.Namespaces[name] = ns
+		} else {
+			res.Namespaces[name] = ns.clone(vars)
+		}
+	}
+	for _, ns := range res.Namespaces {
+		if ns == res.CoreNamespace {
+			continue
+		}
+		for name, vr := range ns.mappings {
+			if c, ok := vars[vr]; ok {
+				ns.mappings[name] = c
+			}
+		}
+		for name, alias := range ns.aliases {
+			if c, ok := res.Namespaces[alias.Name.name]; ok {
+				ns.aliases[name] = c
+			}
+		}
+	}
+	return &res
+}
+
+// Returns the type named name: either a built-in one
+// or defined in this environment (nil if there is no such type).
+func (env *Env) FindType(name string) *Type {
+	if t, ok := env.types[name]; ok {
+		return t
+	}
+	return TYPES[name]
+}
+
 func (env *Env) CurrentNamespace() *Namespace {
-	return AssertNamespace(env.ns.Value, "")
+	return AssertNamespace(env.ns.Resolve(), "")
+}
+
+// Returns the writer that errors and linter warnings are printed to:
+// the current value of *err*.
+func (env *Env) errWriter() io.Writer {
+	return AssertIOWriter(env.stderr.Resolve(), "")
 }
 
 func (env *Env) EnsureNamespace(sym Symbol) *Namespace {
@@ -26,17 +26,23 @@ type (
 	Runtime struct {
 		callstack   *Callstack
 		currentExpr Expr
+		bindings    *BindingFrame
 	}
 )
 
-var RT *Runtime = &Runtime{
-	callstack: &Callstack{frames: make([]Frame, 0, 50)},
+var RT *Runtime = newRuntime()
+
+func newRuntime() *Runtime {
+	return &Runtime{
+		callstack: &Callstack{frames: make([]Frame, 0, 50)},
+	}
 }
 
 func (rt *Runtime) clone() *Runtime {
 	return &Runtime{
 		callstack:   rt.callstack.clone(),
 		currentExpr: rt.currentExpr,
+		bindings:    rt.bindings,
 	}
 }
 
@@ -228,7 +234,7 @@ func (expr *SetExpr) Eval(env *LocalEnv) Object {
 
 func (expr *DefExpr) Eval(env *LocalEnv) Object {
 	if expr.value != nil {
-		expr.vr.Value = Eval(expr.value, env)
+		expr.vr.BindRoot(Eval(expr.value, env))
 	}
 	meta := EmptyArrayMap()
 	meta.Add(MakeKeyword("line"), Int{I: expr.startLine})
@@ -245,6 +251,13 @@ func (expr *DefExpr) Eval(env *LocalEnv) Object {
 	return expr.vr
 }
 
+// def calls the var's validator and watches (see Var.BindRoot)
+// while DefExpr is the current expression, and calling them pushes
+// a frame, which requires the current expression to be Traceable.
+func (expr *DefExpr) Name() string {
+	return "def"
+}
+
 func (expr *MetaExpr) Eval(env *LocalEnv) Object {
 	meta := Eval(expr.meta, env)
 	res := Eval(expr.expr, env)
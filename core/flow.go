@@ -0,0 +1,121 @@
+package core
+
+// Linter-only control flow checks. They run over a fully parsed
+// top level form and report forms that can never be evaluated
+// because a preceding form always throws, as well as recur forms
+// that are not in tail position.
+
+func alwaysThrows(expr Expr) bool {
+	switch expr := expr.(type) {
+	case *ThrowExpr:
+		return true
+	case *MetaExpr:
+		return alwaysThrows(expr.expr)
+	case *DoExpr:
+		return anyThrows(expr.body)
+	case *LetExpr:
+		return anyThrows(expr.values) || anyThrows(expr.body)
+	case *LoopExpr:
+		return anyThrows(expr.values)
+	case *IfExpr:
+		return alwaysThrows(expr.cond) || (alwaysThrows(expr.positive) && alwaysThrows(expr.negative))
+	}
+	return false
+}
+
+func anyThrows(exprs []Expr) bool {
+	for _, expr := range exprs {
+		if alwaysThrows(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkBodyFlow(body []Expr, isTail bool) {
+	reported := false
+	for i, expr := range body {
+		last := i == len(body)-1
+		checkFlow(expr, isTail && last)
+		if !reported && !last && alwaysThrows(expr) {
+			printParseWarning(body[i+1].Pos(), "unreachable code")
+			reported = true
+		}
+	}
+}
+
+func checkSeqFlow(exprs []Expr) {
+	for _, expr := range exprs {
+		checkFlow(expr, false)
+	}
+}
+
+func checkFlow(expr Expr, isTail bool) {
+	switch expr := expr.(type) {
+	case *VectorExpr:
+		checkSeqFlow(expr.v)
+	case *MapExpr:
+		checkSeqFlow(expr.keys)
+		checkSeqFlow(expr.values)
+	case *SetExpr:
+		checkSeqFlow(expr.elements)
+	case *IfExpr:
+		checkFlow(expr.cond, false)
+		checkFlow(expr.positive, isTail)
+		checkFlow(expr.negative, isTail)
+	case *DefExpr:
+		if expr.value != nil {
+			checkFlow(expr.value, false)
+		}
+	case *CallExpr:
+		checkFlow(expr.callable, false)
+		// Args of unknown callables, such as macros that the linter
+		// only has stubs for (letfn, defmethod, etc), are not necessarily
+		// evaluated as args. They may well be fn bodies, where recur
+		// is in tail position, so they are not checked.
+		if !isUnknownCallable(expr.callable) {
+			checkSeqFlow(expr.args)
+		}
+	case *RecurExpr:
+		checkSeqFlow(expr.args)
+		if !isTail {
+			printParseWarning(expr.Pos(), "Can only recur from tail position")
+		}
+	case *MetaExpr:
+		checkFlow(expr.meta, false)
+		checkFlow(expr.expr, isTail)
+	case *DoExpr:
+		checkBodyFlow(expr.body, isTail)
+	case *FnExpr:
+		for _, arity := range expr.arities {
+			checkBodyFlow(arity.body, true)
+		}
+		if expr.variadic != nil {
+			checkBodyFlow(expr.variadic.body, true)
+		}
+	case *LetExpr:
+		checkSeqFlow(expr.values)
+		checkBodyFlow(expr.body, isTail)
+	case *LoopExpr:
+		checkSeqFlow(expr.values)
+		checkBodyFlow(expr.body, true)
+	case *ThrowExpr:
+		checkFlow(expr.e, false)
+	case *TryExpr:
+		// recur can't cross try, so nothing inside it is in tail
+		// position, including the last form of the body. recur in
+		// catch and finally clauses is already reported by the parser
+		// ("Cannot recur across try"), and the value of finally is
+		// discarded anyway. Loops and fns nested inside try
+		// have their own tail positions.
+		checkBodyFlow(expr.body, false)
+		for _, catchExpr := range expr.catches {
+			checkBodyFlow(catchExpr.body, false)
+		}
+		checkBodyFlow(expr.finallyExpr, false)
+	}
+}
+
+func WarnOnControlFlow(expr Expr) {
+	checkFlow(expr, false)
+}
@@ -0,0 +1,383 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// Source code formatter. It works on the lossless syntax tree
+// and only ever changes whitespace: it never adds or removes
+// line breaks except for collapsing trailing closing delimiters
+// and limiting the number of consecutive blank lines.
+
+type (
+	// Indentation rule for the body of a form. Inner rule always
+	// indents the body by two spaces. Block rule does the same unless
+	// there are more than Args arguments on the first line of the form,
+	// in which case the arguments are aligned like function arguments.
+	IndentRule struct {
+		Inner bool
+		Args  int
+	}
+	FormatOptions struct {
+		Indents       map[string]IndentRule
+		MaxBlankLines int
+	}
+	formatter struct {
+		b      bytes.Buffer
+		column int
+		// Indentation of the lines inside the innermost collection.
+		indent int
+		// Length of the output up to the end of the last token.
+		// Whitespace before that comes from the source (e.g. char
+		// literal \ followed by space) and is never trimmed.
+		tokenEnd int
+		opts     *FormatOptions
+	}
+)
+
+func innerIndent() IndentRule {
+	return IndentRule{Inner: true}
+}
+
+func blockIndent(args int) IndentRule {
+	return IndentRule{Args: args}
+}
+
+var defaultIndents = map[string]IndentRule{
+	"def":             innerIndent(),
+	"defn":            innerIndent(),
+	"defn-":           innerIndent(),
+	"defmacro":        innerIndent(),
+	"defmulti":        innerIndent(),
+	"defmethod":       innerIndent(),
+	"defonce":         innerIndent(),
+	"defprotocol":     innerIndent(),
+	"defrecord":       innerIndent(),
+	"deftype":         innerIndent(),
+	"definterface":    innerIndent(),
+	"deftest":         innerIndent(),
+	"extend":          innerIndent(),
+	"extend-protocol": innerIndent(),
+	"extend-type":     innerIndent(),
+	"fn":              innerIndent(),
+	"fn*":             innerIndent(),
+	"letfn":           innerIndent(),
+	"ns":              innerIndent(),
+	"proxy":           innerIndent(),
+	"reify":           innerIndent(),
+	"comment":         innerIndent(),
+	"do":              blockIndent(0),
+	"try":             blockIndent(0),
+	"finally":         blockIndent(0),
+	"cond":            blockIndent(0),
+	"delay":           blockIndent(0),
+	"future":          blockIndent(0),
+	"go":              blockIndent(0),
+	"thread":          blockIndent(0),
+	"binding":         blockIndent(1),
+	"case":            blockIndent(1),
+	"cond->":          blockIndent(1),
+	"cond->>":         blockIndent(1),
+	"doseq":           blockIndent(1),
+	"dotimes":         blockIndent(1),
+	"doto":            blockIndent(1),
+	"for":             blockIndent(1),
+	"go-loop":         blockIndent(1),
+	"if":              blockIndent(1),
+	"if-let":          blockIndent(1),
+	"if-not":          blockIndent(1),
+	"if-some":         blockIndent(1),
+	"let":             blockIndent(1),
+	"let*":            blockIndent(1),
+	"locking":         blockIndent(1),
+	"loop":            blockIndent(1),
+	"loop*":           blockIndent(1),
+	"testing":         blockIndent(1),
+	"when":            blockIndent(1),
+	"when-first":      blockIndent(1),
+	"when-let":        blockIndent(1),
+	"when-not":        blockIndent(1),
+	"when-some":       blockIndent(1),
+	"while":           blockIndent(1),
+	"with-bindings":   blockIndent(1),
+	"with-open":       blockIndent(1),
+	"with-out-str":    blockIndent(0),
+	"with-redefs":     blockIndent(1),
+	"as->":            blockIndent(2),
+	"catch":           blockIndent(2),
+	"condp":           blockIndent(2),
+}
+
+func DefaultFormatOptions() *FormatOptions {
+	res := &FormatOptions{
+		Indents:       make(map[string]IndentRule),
+		MaxBlankLines: 1,
+	}
+	for k, v := range defaultIndents {
+		res.Indents[k] = v
+	}
+	return res
+}
+
+// Applies formatter config (the value of :format key of .joker file).
+// :indents is a map from symbols to either the number of arguments
+// of a block form or :inner. :max-blank-lines limits the number
+// of consecutive blank lines.
+func (opts *FormatOptions) Configure(config Map) {
+	if ok, indents := config.Get(MakeKeyword("indents")); ok {
+		if m, ok := indents.(Map); ok {
+			for iter := m.Iter(); iter.HasNext(); {
+				p := iter.Next()
+				switch v := p.value.(type) {
+				case Int:
+					opts.Indents[p.key.ToString(false)] = blockIndent(v.I)
+				case Keyword:
+					if v.Equals(MakeKeyword("inner")) {
+						opts.Indents[p.key.ToString(false)] = innerIndent()
+					}
+				}
+			}
+		}
+	}
+	if ok, n := config.Get(MakeKeyword("max-blank-lines")); ok {
+		if n, ok := n.(Int); ok && n.I >= 0 {
+			opts.MaxBlankLines = n.I
+		}
+	}
+}
+
+// Looks up indentation rule for form's head. Qualified symbols
+// fall back to the rule for their unqualified name.
+func (opts *FormatOptions) indentRule(head *SyntaxNode) (IndentRule, bool) {
+	if head.Kind != SyntaxSymbol {
+		return IndentRule{}, false
+	}
+	if rule, ok := opts.Indents[head.Text]; ok {
+		return rule, true
+	}
+	if i := strings.LastIndexByte(head.Text, '/'); i > 0 {
+		rule, ok := opts.Indents[head.Text[i+1:]]
+		return rule, ok
+	}
+	return IndentRule{}, false
+}
+
+func (f *formatter) write(s string) {
+	f.b.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		f.column = utf8.RuneCountInString(s[i+1:])
+	} else {
+		f.column += utf8.RuneCountInString(s)
+	}
+}
+
+// Writes source text of a token.
+func (f *formatter) token(s string) {
+	f.write(s)
+	f.tokenEnd = f.b.Len()
+}
+
+// Removes spaces written by formatter at the end of the line.
+func (f *formatter) trimTrailingSpaces() {
+	data := f.b.Bytes()
+	n := len(data)
+	for n > f.tokenEnd && (data[n-1] == ' ' || data[n-1] == '\t') {
+		n--
+	}
+	f.column -= len(data) - n
+	f.b.Truncate(n)
+}
+
+// Writes count line breaks (capped by blank lines limit)
+// followed by indentation.
+func (f *formatter) newline(count int, indent int) {
+	if count > f.opts.MaxBlankLines+1 {
+		count = f.opts.MaxBlankLines + 1
+	}
+	f.trimTrailingSpaces()
+	f.write(strings.Repeat("\n", count) + strings.Repeat(" ", indent))
+}
+
+// Normalizes whitespace that doesn't contain line breaks
+// to a single space, keeping commas.
+func (f *formatter) space(ws string) {
+	f.write(strings.Repeat(",", strings.Count(ws, ",")) + " ")
+}
+
+func isCollectionKind(kind SyntaxKind) bool {
+	switch kind {
+	case SyntaxList, SyntaxVector, SyntaxMap, SyntaxSet, SyntaxFn, SyntaxConditional, SyntaxNamespacedMap:
+		return true
+	}
+	return false
+}
+
+func isListKind(kind SyntaxKind) bool {
+	return kind == SyntaxList || kind == SyntaxFn
+}
+
+// Returns the number of forms that follow the first form
+// on the same line.
+func argsOnFirstLine(n *SyntaxNode) int {
+	res := -1
+	for _, child := range n.Children {
+		if child.Kind == SyntaxComment || child.Kind == SyntaxWhitespace && strings.ContainsRune(child.Text, '\n') {
+			if res >= 0 {
+				break
+			}
+			continue
+		}
+		if !child.IsTrivia() {
+			res++
+		}
+	}
+	if res < 0 {
+		return 0
+	}
+	return res
+}
+
+func (f *formatter) node(n *SyntaxNode) {
+	switch {
+	case n.Kind == SyntaxComment:
+		f.token(strings.TrimRight(n.Text, " \t\r"))
+	case isCollectionKind(n.Kind):
+		f.collection(n)
+	case len(n.Children) > 0:
+		f.prefixed(n)
+	default:
+		f.token(n.Text)
+	}
+}
+
+// Line breaks inside prefixed forms (e.g. between metadata
+// and the form it's attached to) are indented like the lines
+// of the enclosing collection.
+func (f *formatter) prefixed(n *SyntaxNode) {
+	f.token(n.Text)
+	var prev *SyntaxNode
+	for _, child := range n.Children {
+		if child.Kind == SyntaxWhitespace {
+			if nl := strings.Count(child.Text, "\n"); nl > 0 {
+				f.newline(nl, f.indent)
+			} else if prev != nil {
+				f.write(" ")
+			}
+			prev = nil
+			continue
+		}
+		if prev != nil {
+			f.write(" ")
+		}
+		f.node(child)
+		prev = child
+	}
+}
+
+func (f *formatter) collection(n *SyntaxNode) {
+	start := f.column
+	text := n.Text
+	if n.Kind == SyntaxConditional || n.Kind == SyntaxNamespacedMap {
+		text = strings.Join(strings.Fields(text), "")
+	}
+	f.token(text)
+	indent := f.column
+	alignWithFirstArg := false
+	if forms := n.Forms(); isListKind(n.Kind) && len(forms) > 0 {
+		rule, ok := f.opts.indentRule(forms[0])
+		switch {
+		case ok && (rule.Inner || argsOnFirstLine(n) <= rule.Args):
+			indent = start + 2
+		case forms[0].Kind == SyntaxVector:
+			// Function arity, e.g. ([x] body).
+			indent = start + 2
+		case forms[0].Kind == SyntaxSymbol || forms[0].Kind == SyntaxKeyword:
+			alignWithFirstArg = argsOnFirstLine(n) > 0
+		}
+	}
+	outerIndent := f.indent
+	defer func() { f.indent = outerIndent }()
+	formIndex := 0
+	var prev *SyntaxNode
+	for i, child := range n.Children {
+		if child.Kind == SyntaxWhitespace {
+			nl := strings.Count(child.Text, "\n")
+			switch {
+			case i == len(n.Children)-1 && (prev == nil || prev.Kind != SyntaxComment):
+				// Trailing closing delimiters are collapsed.
+			case nl > 0:
+				f.newline(nl, indent)
+			case prev != nil && i < len(n.Children)-1:
+				f.space(child.Text)
+			}
+			prev = child
+			continue
+		}
+		if prev != nil && prev.Kind != SyntaxWhitespace {
+			if prev.Kind == SyntaxComment {
+				f.newline(1, indent)
+			} else {
+				f.write(" ")
+			}
+		}
+		if !child.IsTrivia() {
+			if formIndex == 1 && alignWithFirstArg {
+				indent = f.column
+			}
+			formIndex++
+		}
+		f.indent = indent
+		f.node(child)
+		prev = child
+	}
+	if prev != nil && prev.Kind == SyntaxComment {
+		f.newline(1, indent)
+	}
+	f.token(n.Close)
+}
+
+func (f *formatter) file(n *SyntaxNode) {
+	var prev *SyntaxNode
+	for _, child := range n.Children {
+		if child.Kind == SyntaxWhitespace {
+			if prev != nil {
+				if nl := strings.Count(child.Text, "\n"); nl > 0 {
+					f.newline(nl, 0)
+				} else {
+					f.write(" ")
+				}
+			}
+			prev = child
+			continue
+		}
+		if prev != nil && prev.Kind != SyntaxWhitespace {
+			if prev.Kind == SyntaxComment {
+				f.newline(1, 0)
+			} else {
+				f.write(" ")
+			}
+		}
+		f.node(child)
+		prev = child
+	}
+	f.trimTrailingSpaces()
+	data := f.b.Bytes()
+	end := len(data)
+	for end > f.tokenEnd && data[end-1] == '\n' {
+		end--
+	}
+	f.b.Truncate(end)
+	if end > 0 {
+		f.b.WriteByte('\n')
+	}
+}
+
+// Returns formatted source code of the syntax tree
+// returned by ReadCST.
+func FormatSyntaxTree(n *SyntaxNode, opts *FormatOptions) string {
+	f := &formatter{opts: opts}
+	f.file(n)
+	return f.b.String()
+}
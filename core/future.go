@@ -0,0 +1,179 @@
+package core
+
+import (
+	"time"
+	"unsafe"
+)
+
+type (
+	Future struct {
+		done  chan struct{}
+		value Object
+		// Error (or any other panic) raised by the computation.
+		// It is rethrown when the future is dereferenced.
+		err interface{}
+	}
+	Promise struct {
+		done  chan struct{}
+		value Object
+	}
+	BlockingDeref interface {
+		DerefWithTimeout(timeout time.Duration, timeoutValue Object) Object
+	}
+)
+
+// Waits until done is closed (returns true) or timeout
+// expires (returns false). Negative timeout means no timeout.
+func wait(done chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	default:
+	}
+	res := true
+	RunUnlocked(func() {
+		if timeout < 0 {
+			<-done
+			return
+		}
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-done:
+		case <-timer.C:
+			res = false
+		}
+	})
+	return res
+}
+
+func isDone(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Runs fn in a new goroutine and returns the future
+// that will hold its result.
+func MakeFuture(fn Callable) *Future {
+	res := &Future{done: make(chan struct{})}
+	goJoker(func() {
+		defer close(res.done)
+		defer func() {
+			if r := recover(); r != nil {
+				res.err = r
+			}
+		}()
+		res.value = fn.Call([]Object{})
+	})
+	return res
+}
+
+func (f *Future) ToString(escape bool) string {
+	if isDone(f.done) && f.err == nil {
+		return "#object[Future {:status :ready, :val " + f.value.ToString(escape) + "}]"
+	}
+	if isDone(f.done) {
+		return "#object[Future {:status :failed}]"
+	}
+	return "#object[Future {:status :pending}]"
+}
+
+func (f *Future) Equals(other interface{}) bool {
+	return f == other
+}
+
+func (f *Future) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (f *Future) GetType() *Type {
+	return TYPES["Future"]
+}
+
+func (f *Future) Hash() uint32 {
+	return hashPtr(uintptr(unsafe.Pointer(f)))
+}
+
+func (f *Future) WithInfo(info *ObjectInfo) Object {
+	return f
+}
+
+func (f *Future) DerefWithTimeout(timeout time.Duration, timeoutValue Object) Object {
+	if !wait(f.done, timeout) {
+		return timeoutValue
+	}
+	if f.err != nil {
+		panic(f.err)
+	}
+	return f.value
+}
+
+func (f *Future) Deref() Object {
+	return f.DerefWithTimeout(-1, NIL)
+}
+
+func (f *Future) IsRealized() bool {
+	return isDone(f.done)
+}
+
+func MakePromise() *Promise {
+	return &Promise{done: make(chan struct{})}
+}
+
+// Sets the value of the promise and releases the goroutines
+// waiting for it. Returns false if the promise has already been
+// delivered.
+func (p *Promise) Deliver(value Object) bool {
+	if isDone(p.done) {
+		return false
+	}
+	p.value = value
+	close(p.done)
+	return true
+}
+
+func (p *Promise) ToString(escape bool) string {
+	if isDone(p.done) {
+		return "#object[Promise {:status :ready, :val " + p.value.ToString(escape) + "}]"
+	}
+	return "#object[Promise {:status :pending}]"
+}
+
+func (p *Promise) Equals(other interface{}) bool {
+	return p == other
+}
+
+func (p *Promise) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (p *Promise) GetType() *Type {
+	return TYPES["Promise"]
+}
+
+func (p *Promise) Hash() uint32 {
+	return hashPtr(uintptr(unsafe.Pointer(p)))
+}
+
+func (p *Promise) WithInfo(info *ObjectInfo) Object {
+	return p
+}
+
+func (p *Promise) DerefWithTimeout(timeout time.Duration, timeoutValue Object) Object {
+	if !wait(p.done, timeout) {
+		return timeoutValue
+	}
+	return p.value
+}
+
+func (p *Promise) Deref() Object {
+	return p.DerefWithTimeout(-1, NIL)
+}
+
+func (p *Promise) IsRealized() bool {
+	return isDone(p.done)
+}
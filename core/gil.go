@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Joker code is evaluated by one goroutine at a time: the one that
+// holds the global interpreter lock. Every goroutine has its own
+// runtime (call stack) and reader state, which are made current
+// (see globalState) when it acquires the lock. Namespaces, vars, atoms
+// and other mutable objects are only ever touched by the goroutine
+// holding the lock. Operations that may block for a long time
+// (waiting for processes, futures and promises, reading user input)
+// release the lock with RunUnlocked, which is what allows
+// goroutines to run in parallel.
+
+var (
+	gil sync.Mutex
+	// Id of the goroutine holding the lock (0 if none).
+	gilOwner int64
+	// State of the globals when no goroutine holds the lock.
+	outsideState globalState
+)
+
+// Returns the id of the calling goroutine. Go doesn't expose it,
+// so it's parsed from the first line of the stack trace
+// ("goroutine 42 [running]:").
+func goroutineId() int64 {
+	var buf [64]byte
+	s := buf[:runtime.Stack(buf[:], false)]
+	s = bytes.TrimPrefix(s, []byte("goroutine "))
+	s = s[:bytes.IndexByte(s, ' ')]
+	id, _ := strconv.ParseInt(string(s), 10, 64)
+	return id
+}
+
+func gilHeld() bool {
+	return atomic.LoadInt64(&gilOwner) != 0
+}
+
+// Returns true if the calling goroutine holds the lock.
+func holdsGIL() bool {
+	return atomic.LoadInt64(&gilOwner) == goroutineId()
+}
+
+// Acquires the lock and makes own state current
+// (or keeps the current state if own is nil).
+func lockGIL(own *globalState) {
+	gil.Lock()
+	atomic.StoreInt64(&gilOwner, goroutineId())
+	outsideState = currentGlobalState()
+	if own != nil {
+		own.restore()
+	}
+}
+
+// Releases the lock and returns the state of the goroutine
+// that held it.
+func unlockGIL() globalState {
+	own := currentGlobalState()
+	outsideState.restore()
+	atomic.StoreInt64(&gilOwner, 0)
+	gil.Unlock()
+	return own
+}
+
+// Makes own state current, acquiring the lock unless the calling
+// goroutine already holds it (which is the case when a Go function
+// called by Joker code calls back into an interpreter).
+// Returns the function that restores the previous state.
+func enterGIL(own *globalState) func() {
+	if holdsGIL() {
+		prev := currentGlobalState()
+		own.restore()
+		return prev.restore
+	}
+	lockGIL(own)
+	return func() { unlockGIL() }
+}
+
+// Runs f with the global interpreter lock released, so that other
+// goroutines can evaluate code while f blocks. f must not use
+// any Joker objects or interpreter state.
+func RunUnlocked(f func()) {
+	if !holdsGIL() {
+		f()
+		return
+	}
+	own := unlockGIL()
+	defer lockGIL(&own)
+	f()
+}
+
+// Runs fn in a new goroutine that evaluates code in the current
+// environment. The goroutine's call stack starts as a copy of the
+// current one, so that stack traces show where it was started,
+// and it gets copies of the current thread-local bindings.
+func goJoker(fn func()) {
+	if !gilHeld() {
+		// The goroutine evaluating the code that starts the first
+		// goroutine becomes the owner of the lock.
+		lockGIL(nil)
+	}
+	state := currentGlobalState()
+	state.rt = RT.fork()
+	state.localBindings = nil
+	state.posStack = make([]pos, 0, 8)
+	state.args = nil
+	go func() {
+		lockGIL(&state)
+		defer unlockGIL()
+		fn()
+	}()
+}
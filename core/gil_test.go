@@ -0,0 +1,73 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func newAsyncInterpreter(t *testing.T) *Interpreter {
+	in := NewInterpreter()
+	evalString(t, in, `(defn wait [ms] (joker.async/<!! (joker.async/timeout ms)))`)
+	return in
+}
+
+func TestFutures(t *testing.T) {
+	in := newAsyncInterpreter(t)
+	assertEval(t, in, `@(future (+ 1 2))`, `3`)
+	assertEval(t, in, `(let [f (future 1)] @f (realized? f))`, `true`)
+	assertEval(t, in, `(let [f (future (wait 1000))] (realized? f))`, `false`)
+	assertEval(t, in, `(deref (future (wait 1000) 1) 10 :timeout)`, `:timeout`)
+	assertEval(t, in, `(deref (future 1) 1000 :timeout)`, `1`)
+	// Errors are rethrown on deref.
+	assertEval(t, in, `(try @(future (throw (ex-info "boom" {}))) (catch Error e :caught))`, `:caught`)
+}
+
+// Futures get copies of the bindings of the goroutine
+// that starts them, so var-set doesn't leak either way.
+func TestFutureBindings(t *testing.T) {
+	in := newAsyncInterpreter(t)
+	evalString(t, in, `(def ^:dynamic *x* 0)`)
+	assertEval(t, in, `(binding [*x* 1] [@(future (var-set #'*x* 2) *x*) *x*])`, `[2 1]`)
+	assertEval(t, in, `(binding [*x* 1]
+	                     (let [p (promise)
+	                           f (future @p *x*)]
+	                       (var-set #'*x* 2)
+	                       (deliver p nil)
+	                       [@f *x*]))`, `[1 2]`)
+	assertEval(t, in, `(binding [*x* 1] @(future (binding [*x* 3] (var-set #'*x* 4)) *x*))`, `1`)
+	assertEval(t, in, `*x*`, `0`)
+}
+
+func TestPromises(t *testing.T) {
+	in := newAsyncInterpreter(t)
+	assertEval(t, in, `(let [p (promise)] (future (wait 10) (deliver p 1)) @p)`, `1`)
+	assertEval(t, in, `(let [p (promise)] (realized? p))`, `false`)
+	// Only the first delivery counts.
+	assertEval(t, in, `(let [p (promise)] (deliver p 1) (deliver p 2) @p)`, `1`)
+	assertEval(t, in, `(deref (promise) 10 :timeout)`, `:timeout`)
+	assertEval(t, in, `(let [p (promise)] (deliver p nil) (deref p 10 :timeout))`, `nil`)
+}
+
+// Lazy results are realized with doall, since they
+// can only be evaluated by the interpreter.
+func TestParallelFunctions(t *testing.T) {
+	in := newAsyncInterpreter(t)
+	// Results keep the order of the calls, not of their completion.
+	assertEval(t, in, `(doall (pcalls #(do (wait 10) 0) (fn [] 1) (fn [] 2)))`, `(0 1 2)`)
+	assertEval(t, in, `(doall (pvalues (+ 1 1) (do (wait 10) 2) 3))`, `(2 2 3)`)
+	assertEval(t, in, `(doall (pmap inc (range 10)))`, `(1 2 3 4 5 6 7 8 9 10)`)
+	assertEval(t, in, `(doall (pmap + [1 2 3] [10 20]))`, `(11 22)`)
+	// Updates from all goroutines are seen.
+	assertEval(t, in, `(let [n (atom 0)] (doall (pmap (fn [_] (swap! n inc)) (range 100))) @n)`, `100`)
+}
+
+// Goroutines waiting for channels, futures, etc. release
+// the global interpreter lock, so the waits overlap.
+func TestWaitingOverlaps(t *testing.T) {
+	in := newAsyncInterpreter(t)
+	start := time.Now()
+	evalString(t, in, `(doall (pcalls #(wait 200) #(wait 200) #(wait 200)))`)
+	if d := time.Since(start); d >= 550*time.Millisecond {
+		t.Errorf("expected waits to overlap, took %v", d)
+	}
+}
@@ -0,0 +1,21 @@
+package core
+
+import "testing"
+
+// Evaluates src in interpreter in, failing the test on error.
+func evalString(t *testing.T, in *Interpreter, src string) Object {
+	t.Helper()
+	res, err := in.EvalString(src)
+	if err != nil {
+		t.Fatalf("%s: %v", src, err)
+	}
+	return res
+}
+
+// Checks that src evaluates to an object that prints as expected.
+func assertEval(t *testing.T, in *Interpreter, src string, expected string) {
+	t.Helper()
+	if res := evalString(t, in, src).ToString(true); res != expected {
+		t.Errorf("%s: expected %s, got %s", src, expected, res)
+	}
+}
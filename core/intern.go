@@ -1,16 +1,32 @@
 package core
 
+import (
+	"sync"
+)
+
 type (
 	StringPool map[string]*string
 )
 
-var STRINGS StringPool = StringPool{}
+// Interned names of symbols and keywords. The pool is shared
+// by all interpreters and can be used from any goroutine.
+var (
+	STRINGS      StringPool = StringPool{}
+	stringsMutex sync.RWMutex
+)
 
 func (p StringPool) Intern(s string) *string {
+	stringsMutex.RLock()
 	ss, exists := p[s]
+	stringsMutex.RUnlock()
 	if exists {
 		return ss
 	}
+	stringsMutex.Lock()
+	defer stringsMutex.Unlock()
+	if ss, exists := p[s]; exists {
+		return ss
+	}
 	p[s] = &s
 	return &s
 }
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+type (
+	// Package level state that the reader, parser and evaluator
+	// work with. Every Interpreter has its own copy of it.
+	globalState struct {
+		env           *Env
+		rt            *Runtime
+		linterMode    bool
+		dialect       Dialect
+		localBindings *Bindings
+		knownMacros   *Var
+		jsGlobals     *Var
+		jsMethods     *Var
+		posStack      []pos
+		args          map[int]Symbol
+	}
+	// Interpreter is an instance of Joker that can be embedded
+	// in Go programs. Each Interpreter has its own namespaces, vars,
+	// types and runtime, so definitions made in one of them
+	// are not visible in the others or in GLOBAL_ENV.
+	// Interpreters take turns evaluating code in the package level
+	// variables (GLOBAL_ENV, RT, etc.), so calls to all of them
+	// are serialized by the global interpreter lock (see gil.go).
+	// Go functions called by Joker code (e.g. registered with
+	// RegisterNamespace) can call back into any interpreter.
+	// Lazy sequences returned by EvalString, Call, etc. must be
+	// realized (e.g. with doall) before they are returned, since
+	// realizing them outside of the interpreter evaluates code
+	// without holding the lock.
+	Interpreter struct {
+		state globalState
+	}
+)
+
+func currentGlobalState() globalState {
+	return globalState{
+		env:           GLOBAL_ENV,
+		rt:            RT,
+		linterMode:    LINTER_MODE,
+		dialect:       DIALECT,
+		localBindings: LOCAL_BINDINGS,
+		knownMacros:   KNOWN_MACROS,
+		jsGlobals:     JS_GLOBALS,
+		jsMethods:     JS_METHODS,
+		posStack:      posStack,
+		args:          ARGS,
+	}
+}
+
+func (s *globalState) restore() {
+	GLOBAL_ENV = s.env
+	RT = s.rt
+	LINTER_MODE = s.linterMode
+	DIALECT = s.dialect
+	LOCAL_BINDINGS = s.localBindings
+	KNOWN_MACROS = s.knownMacros
+	JS_GLOBALS = s.jsGlobals
+	JS_METHODS = s.jsMethods
+	posStack = s.posStack
+	ARGS = s.args
+}
+
+// Makes interpreter's state current and returns the function
+// that restores the previous one. Every call gets its own
+// runtime and reader state, since several goroutines may be
+// evaluating code in the same interpreter (taking turns
+// at the global interpreter lock).
+func (in *Interpreter) enter() func() {
+	state := in.state
+	state.rt = newRuntime()
+	state.posStack = make([]pos, 0, 8)
+	return enterGIL(&state)
+}
+
+// Turns Joker errors (see Error) panicked by the evaluator
+// into err. Must be deferred directly.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(Error); ok {
+			*err = e
+			return
+		}
+		panic(r)
+	}
+}
+
+func isBuiltinNamespace(ns *Namespace) bool {
+	name := ns.Name.Name()
+	// joker.core and joker.async are evaluated by initCoreNamespace.
+	return strings.HasPrefix(name, "joker.") && name != "joker.core" && name != "joker.async"
+}
+
+// Makes a new environment current: built-in namespaces are copied
+// from builtins, and the core namespace is initialized from scratch.
+// Must be called with the GIL held.
+func initGlobalState(builtins *Env) {
+	env := NewEnv(MakeSymbol("user"), os.Stdout, os.Stdin, os.Stderr)
+	env.args.Value = NIL
+	// Built-in namespaces implemented in Go (joker.string, joker.os, etc.)
+	// only hold procs, so the new environment gets copies of them
+	// instead of evaluating anything.
+	vars := make(map[*Var]*Var)
+	for name, ns := range builtins.Namespaces {
+		if isBuiltinNamespace(ns) {
+			env.Namespaces[name] = ns.clone(vars)
+		}
+	}
+	GLOBAL_ENV = env
+	RT = newRuntime()
+	LINTER_MODE = false
+	DIALECT = JOKER
+	LOCAL_BINDINGS = nil
+	KNOWN_MACROS = nil
+	JS_GLOBALS = nil
+	JS_METHODS = nil
+	posStack = make([]pos, 0, 8)
+	ARGS = nil
+	initCoreNamespace()
+	env.FindNamespace(MakeSymbol("user")).ReferAll(env.CoreNamespace)
+}
+
+// Creates a new interpreter with user as the current namespace.
+func NewInterpreter() *Interpreter {
+	in := &Interpreter{}
+	defer enterGIL(&in.state)()
+	initGlobalState(outsideState.env)
+	in.state = currentGlobalState()
+	return in
+}
+
+// Reads, parses and evaluates all forms from r and returns the value
+// of the last one (nil if there are no forms). filename is only used
+// in error messages. Evaluation stops at the first error.
+func (in *Interpreter) EvalReader(r io.Reader, filename string) (res Object, err error) {
+	defer in.enter()()
+	// Reading and parsing can also happen during evaluation
+	// (read-string, eval), so errors of any kind may come from TryEval.
+	defer recoverError(&err)
+	reader := NewReader(bufio.NewReader(r), filename)
+	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
+	res = NIL
+	for {
+		obj, err := TryRead(reader)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		expr, err := TryParse(obj, parseContext)
+		if err != nil {
+			return nil, err
+		}
+		res, err = TryEval(expr)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Evaluates all forms in src and returns the value of the last one.
+func (in *Interpreter) EvalString(src string) (Object, error) {
+	return in.EvalReader(strings.NewReader(src), "<string>")
+}
+
+// Calls fn (e.g. a function returned by EvalString) with args
+// in the context of the interpreter.
+func (in *Interpreter) Call(fn Callable, args ...Object) (res Object, err error) {
+	defer in.enter()()
+	defer recoverError(&err)
+	return fn.Call(args), nil
+}
+
+// Looks up a var by name, e.g. "joker.core/map" or "config".
+// Unqualified names are resolved in the current namespace.
+func (in *Interpreter) LookupVar(name string) (*Var, bool) {
+	defer in.enter()()
+	return GLOBAL_ENV.Resolve(MakeSymbol(name))
+}
+
+// Creates (or extends) namespace name with vars holding procs from fns,
+// so that Joker code can call them, e.g. (my.ns/fn-name 1 2).
+func (in *Interpreter) RegisterNamespace(name string, fns map[string]Proc) *Namespace {
+	defer in.enter()()
+	ns := GLOBAL_ENV.EnsureNamespace(MakeSymbol(name))
+	for fnName, fn := range fns {
+		ns.Intern(MakeSymbol(fnName)).Value = fn
+	}
+	return ns
+}
+
+// Loads native extension from Go plugin file path into the interpreter
+// (see LoadNative). Extensions loaded with LoadNative outside of
+// interpreters (e.g. listed in .joker config file) are not visible to them.
+func (in *Interpreter) LoadNative(path string) error {
+	defer in.enter()()
+	return LoadNative(path)
+}
+
+// Converts obj to Go value in the context of the interpreter
+// (see FromObject), so that Go functions converted from Joker
+// functions evaluate them in the interpreter.
+func (in *Interpreter) FromObject(obj Object, ptr interface{}) error {
+	defer in.enter()()
+	return FromObject(obj, ptr)
+}
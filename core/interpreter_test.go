@@ -0,0 +1,152 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestInterpretersConcurrently(t *testing.T) {
+	interpreters := []*Interpreter{NewInterpreter(), NewInterpreter()}
+	for i, in := range interpreters {
+		evalString(t, in, fmt.Sprintf(`
+(def id %d)
+(def calls (atom 0))
+(defn f [] (swap! calls inc) id)`, i))
+	}
+	var wg sync.WaitGroup
+	for i, in := range interpreters {
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func(i int, in *Interpreter) {
+				defer wg.Done()
+				for k := 0; k < 25; k++ {
+					res, err := in.EvalString(`[(f) @(future (f)) (ns-name *ns*)]`)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if expected := fmt.Sprintf("[%d %d user]", i, i); res.ToString(true) != expected {
+						t.Errorf("expected %s, got %s", expected, res.ToString(true))
+						return
+					}
+				}
+			}(i, in)
+		}
+	}
+	wg.Wait()
+	for _, in := range interpreters {
+		if res := evalString(t, in, `@calls`).ToString(true); res != "200" {
+			t.Errorf("expected 200 calls, got %s", res)
+		}
+	}
+}
+
+func TestInterpreterCopiesBuiltinNamespaces(t *testing.T) {
+	// Futures started by other tests may be running.
+	lockGIL(nil)
+	ns := GLOBAL_ENV.EnsureNamespace(MakeSymbol("joker.interpreter-test"))
+	vr := ns.Intern(MakeSymbol("v"))
+	vr.Value = Int{I: 1}
+	vr.SetValidator(Proc(func(args []Object) Object {
+		return Bool{B: args[0].(Int).I > 0}
+	}))
+	unlockGIL()
+	in := NewInterpreter()
+	copied, ok := in.LookupVar("joker.interpreter-test/v")
+	if !ok || copied == vr || copied.ns == ns {
+		t.Fatalf("expected a copy of %s", vr.ToString(false))
+	}
+	if copied.validator == nil {
+		t.Errorf("expected %s to keep the validator", copied.ToString(false))
+	}
+	evalString(t, in, `(alter-var-root #'joker.interpreter-test/v inc)`)
+	if _, err := in.EvalString(`(alter-var-root #'joker.interpreter-test/v -)`); err == nil {
+		t.Errorf("expected validator to reject negative value")
+	}
+	if res := evalString(t, in, `joker.interpreter-test/v`).ToString(false); res != "2" {
+		t.Errorf("expected 2, got %s", res)
+	}
+	if vr.Value.ToString(false) != "1" {
+		t.Errorf("expected the original var to be unchanged, got %s", vr.Value.ToString(false))
+	}
+}
+
+func TestInterpreterTypes(t *testing.T) {
+	in1, in2 := NewInterpreter(), NewInterpreter()
+	evalString(t, in1, `(defrecord Point [x y])`)
+	evalString(t, in2, `(defrecord Point [x y z])`)
+	if res := evalString(t, in1, `(pr-str (->Point 1 2))`).ToString(false); res != "#user.Point{:x 1, :y 2}" {
+		t.Errorf("expected in1's Point, got %s", res)
+	}
+	if res := evalString(t, in2, `(count #user.Point{:x 1 :y 2 :z 3})`).ToString(false); res != "3" {
+		t.Errorf("expected in2's Point, got %s", res)
+	}
+	if _, err := NewInterpreter().EvalString(`user.Point`); err == nil {
+		t.Errorf("expected user.Point to be unknown in a new interpreter")
+	}
+}
+
+func TestInterpreterCallbacks(t *testing.T) {
+	in1, in2 := NewInterpreter(), NewInterpreter()
+	evalString(t, in2, `(def x 2)`)
+	var nested *Interpreter
+	in1.RegisterNamespace("host", map[string]Proc{
+		"x-in-other": func(args []Object) Object {
+			res, err := in2.EvalString(`x`)
+			if err != nil {
+				panic(err)
+			}
+			return res
+		},
+		"eval-in-self": func(args []Object) Object {
+			res, err := in1.EvalString(`(+ x 10)`)
+			if err != nil {
+				panic(err)
+			}
+			return res
+		},
+		"new-interpreter": func(args []Object) Object {
+			nested = NewInterpreter()
+			return NIL
+		},
+	})
+	evalString(t, in1, `(def x 1)`)
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(host/x-in-other)`, `2`},
+		{`[x (host/x-in-other) x]`, `[1 2 1]`},
+		{`(host/eval-in-self)`, `11`},
+		{`@(future (host/x-in-other))`, `2`},
+		{`(do (host/new-interpreter) x)`, `1`},
+	}
+	for _, test := range tests {
+		if res := evalString(t, in1, test.src).ToString(true); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expected, res)
+		}
+	}
+	if res := evalString(t, nested, `(resolve 'x)`).ToString(false); res != "nil" {
+		t.Errorf("expected x to be undefined in nested interpreter, got %s", res)
+	}
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	in := NewInterpreter()
+	fns := []string{
+		`(fn [] (read-string "(1"))`,
+		`(fn [] (eval '(undefined-fn 1)))`,
+		`(fn [] (throw (ex-info "boom" {})))`,
+		`(fn [] (nth [] 1))`,
+	}
+	for _, src := range fns {
+		fn := evalString(t, in, src).(Callable)
+		if _, err := in.Call(fn); err == nil {
+			t.Errorf("%s: expected Call to return error", src)
+		}
+		if _, err := in.EvalString("(" + src + ")"); err == nil {
+			t.Errorf("%s: expected EvalString to return error", src)
+		}
+	}
+}
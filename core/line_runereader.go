@@ -24,7 +24,12 @@ func (lrr *LineRuneReader) ReadRune() (rune, int, error) {
 		lrr.i++
 		return r, utf8.RuneLen(r), nil
 	}
-	line, err := lrr.rl.Readline()
+	var line string
+	var err error
+	// Let futures run while waiting for user input.
+	RunUnlocked(func() {
+		line, err = lrr.rl.Readline()
+	})
 	if err != nil {
 		return EOF, 0, io.EOF
 	}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// Linter lints files in isolation from each other. For every
+	// dialect it keeps a snapshot of the environment with the core
+	// namespace initialized and the linter data for the dialect loaded.
+	// Each file is linted in its own copy of the snapshot, which is
+	// cheaper than initializing the core namespace for every file.
+	// Linting is serialized by the global interpreter lock (see gil.go).
+	Linter struct {
+		mutex     sync.Mutex
+		snapshots map[Dialect]*globalState
+	}
+)
+
+func makeDialectKeyword(dialect Dialect) Keyword {
+	switch dialect {
+	case EDN:
+		return MakeKeyword("clj")
+	case CLJ:
+		return MakeKeyword("clj")
+	case CLJS:
+		return MakeKeyword("cljs")
+	default:
+		return MakeKeyword("joker ")
+	}
+}
+
+// Switches the current environment to linter mode for dialect
+// and loads linter data (declarations of the dialect's core vars
+// and macros).
+func ConfigureLinterMode(dialect Dialect) {
+	LINTER_MODE = true
+	DIALECT = dialect
+	lm, _ := GLOBAL_ENV.Resolve(MakeSymbol("joker.core/*linter-mode*"))
+	lm.Value = Bool{B: true}
+	GLOBAL_ENV.Features = GLOBAL_ENV.Features.Disjoin(MakeKeyword("joker")).Conj(makeDialectKeyword(dialect)).(Set)
+	ProcessLinterData(dialect)
+}
+
+func NewLinter() *Linter {
+	return &Linter{snapshots: make(map[Dialect]*globalState)}
+}
+
+func (l *Linter) snapshot(dialect Dialect) *globalState {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if res, ok := l.snapshots[dialect]; ok {
+		return res
+	}
+	lockGIL(&globalState{})
+	initGlobalState(outsideState.env)
+	ConfigureLinterMode(dialect)
+	res := unlockGIL()
+	l.snapshots[dialect] = &res
+	return &res
+}
+
+// Lints file filename (standard input if filename is "--")
+// as dialect and writes errors and warnings to out.
+func (l *Linter) Lint(filename string, dialect Dialect, out *bytes.Buffer) {
+	var data []byte
+	var err error
+	if filename == "--" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		fmt.Fprintln(out, "Error: ", err)
+		return
+	}
+	state := *l.snapshot(dialect)
+	state.env = state.env.clone()
+	state.rt = newRuntime()
+	state.posStack = make([]pos, 0, 8)
+	lockGIL(&state)
+	defer unlockGIL()
+	env := GLOBAL_ENV
+	// Core namespace is shared by all copies of the snapshot,
+	// so the core vars that linting changes are bound to their
+	// initial values.
+	bindings := EmptyArrayMap()
+	bindings.Add(env.ns, env.Namespaces[env.CurrentNamespace().Name.name])
+	bindings.Add(env.file, NIL)
+	bindings.Add(env.stderr, &Buffer{out})
+	bindings.Add(env.dataReaders, env.dataReaders.Value)
+	bindings.Add(env.defaultDataReaderFn, env.defaultDataReaderFn.Value)
+	if vr := env.CoreNamespace.Resolve("*loaded-libs*"); vr != nil {
+		bindings.Add(vr, vr.Value)
+	}
+	RT.PushBindings(bindings)
+	defer RT.PopBindings()
+	lintReader(bytes.NewReader(data), filename, dialect)
+}
+
+func lintReader(r io.Reader, filename string, dialect Dialect) {
+	phase := PARSE
+	if dialect == EDN {
+		phase = READ
+	}
+	var reader *Reader
+	if filename == "--" {
+		LoadDataReaders(".")
+		reader = NewReader(bufio.NewReader(r), "<stdin>")
+		filename = ""
+	} else {
+		LoadDataReaders(filepath.Dir(filename))
+		reader = NewReader(bufio.NewReader(r), filename)
+	}
+	if ProcessReader(reader, filename, phase) == nil {
+		WarnOnUnusedNamespaces()
+	}
+}
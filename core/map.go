@@ -56,7 +56,7 @@ func mapEquals(m Map, other interface{}) bool {
 		return true
 	}
 	switch otherMap := other.(type) {
-	case Nil:
+	case Nil, *Record:
 		return false
 	case Map:
 		if m.Count() != otherMap.Count() {
@@ -75,13 +75,54 @@ func mapEquals(m Map, other interface{}) bool {
 	}
 }
 
+// Returns the namespace shared by all the keys of m
+// if they are all qualified keywords or symbols, nil otherwise.
+func mapKeysNamespace(m Map) *string {
+	var res *string
+	for iter := m.Iter(); iter.HasNext(); {
+		var ns *string
+		switch k := iter.Next().key.(type) {
+		case Keyword:
+			ns = k.ns
+		case Symbol:
+			ns = k.ns
+		}
+		if ns == nil || (res != nil && ns != res) {
+			return nil
+		}
+		res = ns
+	}
+	return res
+}
+
+func unqualifiedKeyString(key Object) string {
+	switch k := key.(type) {
+	case Keyword:
+		return ":" + *k.name
+	case Symbol:
+		return *k.name
+	}
+	return ""
+}
+
 func mapToString(m Map, escape bool) string {
 	var b bytes.Buffer
+	var ns *string
+	if toBool(GLOBAL_ENV.printNamespaceMaps.Resolve()) {
+		ns = mapKeysNamespace(m)
+	}
+	if ns != nil {
+		b.WriteString("#:" + *ns)
+	}
 	b.WriteRune('{')
 	if m.Count() > 0 {
 		for iter := m.Iter(); ; {
 			p := iter.Next()
-			b.WriteString(p.key.ToString(escape))
+			if ns != nil {
+				b.WriteString(unqualifiedKeyString(p.key))
+			} else {
+				b.WriteString(p.key.ToString(escape))
+			}
 			b.WriteRune(' ')
 			b.WriteString(p.value.ToString(escape))
 			if iter.HasNext() {
@@ -0,0 +1,418 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Conversion between arbitrary Go values and Joker objects.
+// Structs are converted to maps with keyword keys. Key names
+// are taken from `joker` field tags (`joker:"-"` skips the field)
+// or are the same as field names. Slices and arrays become vectors,
+// funcs become procs and nil pointers, slices, maps, funcs and
+// interfaces become nil.
+
+var (
+	objectType = reflect.TypeOf((*Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+const maxInt = int(^uint(0) >> 1)
+
+func fieldKey(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	switch tag := f.Tag.Get("joker"); tag {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return tag, true
+	}
+}
+
+func isNilable(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// Pointer, map or slice that is being converted to Joker object.
+type visit struct {
+	ptr uintptr
+	t   reflect.Type
+	len int
+}
+
+// Converts Go value to Joker object. Panics if v (or anything
+// it contains) can't be converted, e.g. if it's a channel or
+// contains pointers that form a cycle.
+func ToObject(v interface{}) Object {
+	return valueToObject(reflect.ValueOf(v), make(map[visit]bool))
+}
+
+// visiting holds the pointers, maps and slices that contain v,
+// so that cycles can be detected.
+func valueToObject(v reflect.Value, visiting map[visit]bool) Object {
+	if !v.IsValid() || isNilable(v.Kind()) && v.IsNil() {
+		return NIL
+	}
+	if v.Type().Implements(objectType) {
+		return v.Interface().(Object)
+	}
+	if v.Type() == timeType {
+		return MakeTime(v.Interface().(time.Time))
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		key := visit{ptr: v.Pointer(), t: v.Type()}
+		if v.Kind() == reflect.Slice {
+			key.len = v.Len()
+		}
+		if visiting[key] {
+			panic(RT.NewError("Cannot convert Go value of type " + v.Type().String() + " to Joker object: it contains itself"))
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return Bool{B: v.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if int64(int(i)) != i {
+			return &BigInt{b: *big.NewInt(i)}
+		}
+		return Int{I: int(i)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > uint64(maxInt) {
+			res := &BigInt{}
+			res.b.SetUint64(u)
+			return res
+		}
+		return Int{I: int(u)}
+	case reflect.Float32, reflect.Float64:
+		return Double{D: v.Float()}
+	case reflect.String:
+		return String{S: v.String()}
+	case reflect.Slice, reflect.Array:
+		res := EmptyVector
+		for i := 0; i < v.Len(); i++ {
+			res = res.Conjoin(valueToObject(v.Index(i), visiting))
+		}
+		return res
+	case reflect.Map:
+		var res Map = EmptyArrayMap()
+		for _, key := range v.MapKeys() {
+			res = res.Assoc(valueToObject(key, visiting), valueToObject(v.MapIndex(key), visiting)).(Map)
+		}
+		return res
+	case reflect.Struct:
+		var res Map = EmptyArrayMap()
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if name, ok := fieldKey(t.Field(i)); ok {
+				res = res.Assoc(MakeKeyword(name), valueToObject(v.Field(i), visiting)).(Map)
+			}
+		}
+		return res
+	case reflect.Ptr, reflect.Interface:
+		return valueToObject(v.Elem(), visiting)
+	case reflect.Func:
+		return funcToProc(v)
+	}
+	panic(RT.NewError("Cannot convert Go value of type " + v.Type().String() + " to Joker object"))
+}
+
+// Wraps Go function in a proc that converts its arguments
+// to the types of function's parameters. If the last result
+// of the function is an error, it is thrown when not nil.
+// Several remaining results are returned as a vector.
+func funcToProc(fn reflect.Value) Proc {
+	t := fn.Type()
+	return func(args []Object) Object {
+		n := t.NumIn()
+		if t.IsVariadic() {
+			CheckArity(args, n-1, math.MaxInt32)
+		} else {
+			CheckArity(args, n, n)
+		}
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			var argType reflect.Type
+			if t.IsVariadic() && i >= n-1 {
+				argType = t.In(n - 1).Elem()
+			} else {
+				argType = t.In(i)
+			}
+			in[i] = objectToValue(arg, argType)
+		}
+		out := fn.Call(in)
+		if len(out) > 0 && t.Out(len(out)-1) == errorType {
+			if err := out[len(out)-1]; !err.IsNil() {
+				panic(RT.NewError(err.Interface().(error).Error()))
+			}
+			out = out[:len(out)-1]
+		}
+		switch len(out) {
+		case 0:
+			return NIL
+		case 1:
+			return ToObject(out[0].Interface())
+		}
+		res := EmptyVector
+		for _, v := range out {
+			res = res.Conjoin(ToObject(v.Interface()))
+		}
+		return res
+	}
+}
+
+func conversionError(obj Object, t reflect.Type) *EvalError {
+	return RT.NewError(fmt.Sprintf("Cannot convert %s to Go type %s", obj.GetType().ToString(false), t))
+}
+
+// Converts obj to Go value for interface{}. Primitives are converted
+// to their native representation, keywords and symbols to strings
+// (without leading colon), collections to slices and maps.
+// Other objects (functions, etc.) are kept as is.
+func objectToInterface(obj Object) interface{} {
+	switch obj := obj.(type) {
+	case Nil:
+		return nil
+	case Native:
+		return obj.Native()
+	case Keyword:
+		return obj.ToString(false)[1:]
+	case Symbol:
+		return obj.ToString(false)
+	case Map:
+		res := make(map[interface{}]interface{})
+		for iter := obj.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			key := objectToInterface(p.key)
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				key = p.key
+			}
+			res[key] = objectToInterface(p.value)
+		}
+		return res
+	case *Vector, *List, *MapSet:
+		res := []interface{}{}
+		for iter := iter(obj.(Seqable).Seq()); iter.HasNext(); {
+			res = append(res, objectToInterface(iter.Next()))
+		}
+		return res
+	}
+	return obj
+}
+
+func objectToValue(obj Object, t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Interface && t.NumMethod() == 0 {
+		res := reflect.New(t).Elem()
+		if v := objectToInterface(obj); v != nil {
+			res.Set(reflect.ValueOf(v))
+		}
+		return res
+	}
+	if reflect.TypeOf(obj).AssignableTo(t) {
+		return reflect.ValueOf(obj)
+	}
+	if _, ok := obj.(Nil); ok && isNilable(t.Kind()) {
+		return reflect.Zero(t)
+	}
+	if t == timeType {
+		if tm, ok := obj.(Time); ok {
+			return reflect.ValueOf(tm.T)
+		}
+		panic(conversionError(obj, t))
+	}
+	res := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, ok := obj.(Bool); ok {
+			res.SetBool(b.B)
+			return res
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch obj := obj.(type) {
+		case Int:
+			i = int64(obj.I)
+		case *BigInt:
+			if !obj.b.IsInt64() {
+				panic(RT.NewError("Integer overflow: " + obj.ToString(false) + " doesn't fit into Go type " + t.String()))
+			}
+			i = obj.b.Int64()
+		default:
+			panic(conversionError(obj, t))
+		}
+		if res.OverflowInt(i) {
+			panic(RT.NewError("Integer overflow: " + obj.ToString(false) + " doesn't fit into Go type " + t.String()))
+		}
+		res.SetInt(i)
+		return res
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var b big.Int
+		switch obj := obj.(type) {
+		case Int:
+			b.SetInt64(int64(obj.I))
+		case *BigInt:
+			b.Set(&obj.b)
+		default:
+			panic(conversionError(obj, t))
+		}
+		if b.Sign() < 0 || !b.IsUint64() || res.OverflowUint(b.Uint64()) {
+			panic(RT.NewError("Integer overflow: " + obj.ToString(false) + " doesn't fit into Go type " + t.String()))
+		}
+		res.SetUint(b.Uint64())
+		return res
+	case reflect.Float32, reflect.Float64:
+		if n, ok := obj.(Number); ok {
+			res.SetFloat(n.Double().D)
+			return res
+		}
+	case reflect.String:
+		if s, ok := obj.(String); ok {
+			res.SetString(s.S)
+			return res
+		}
+	case reflect.Slice:
+		if s, ok := obj.(Seqable); ok {
+			for iter := iter(s.Seq()); iter.HasNext(); {
+				res = reflect.Append(res, objectToValue(iter.Next(), t.Elem()))
+			}
+			return res
+		}
+	case reflect.Array:
+		if s, ok := obj.(Seqable); ok {
+			i := 0
+			for iter := iter(s.Seq()); iter.HasNext(); i++ {
+				if i >= t.Len() {
+					panic(RT.NewError(fmt.Sprintf("Cannot convert %s to Go type %s: too many elements", obj.GetType().ToString(false), t)))
+				}
+				res.Index(i).Set(objectToValue(iter.Next(), t.Elem()))
+			}
+			return res
+		}
+	case reflect.Map:
+		if m, ok := obj.(Map); ok {
+			res.Set(reflect.MakeMap(t))
+			for iter := m.Iter(); iter.HasNext(); {
+				p := iter.Next()
+				res.SetMapIndex(objectToValue(p.key, t.Key()), objectToValue(p.value, t.Elem()))
+			}
+			return res
+		}
+	case reflect.Struct:
+		if m, ok := obj.(Map); ok {
+			for i := 0; i < t.NumField(); i++ {
+				name, ok := fieldKey(t.Field(i))
+				if !ok {
+					continue
+				}
+				found, v := m.Get(MakeKeyword(name))
+				if !found {
+					found, v = m.Get(String{S: name})
+				}
+				if found {
+					res.Field(i).Set(objectToValue(v, t.Field(i).Type))
+				}
+			}
+			return res
+		}
+	case reflect.Ptr:
+		res.Set(reflect.New(t.Elem()))
+		res.Elem().Set(objectToValue(obj, t.Elem()))
+		return res
+	case reflect.Func:
+		if fn, ok := obj.(Callable); ok {
+			return callableToFunc(fn, t)
+		}
+	}
+	panic(conversionError(obj, t))
+}
+
+// Wraps callable in a Go function of type t. Arguments are converted
+// with ToObject. The function may return at most one value
+// optionally followed by an error. Errors thrown by the callable
+// are returned in the error result if there is one and propagate
+// as panics otherwise. Like goroutines started by Joker code
+// (see goJoker), the function can be called from any goroutine
+// and evaluates fn in the environment it was converted in.
+func callableToFunc(fn Callable, t reflect.Type) reflect.Value {
+	outs := t.NumOut()
+	hasError := outs > 0 && t.Out(outs-1) == errorType
+	if hasError {
+		outs--
+	}
+	if outs > 1 {
+		panic(RT.NewError("Cannot convert function to Go type " + t.String() + ": too many results"))
+	}
+	state := currentGlobalState()
+	state.rt = RT.fork()
+	state.localBindings = nil
+	state.args = nil
+	return reflect.MakeFunc(t, func(in []reflect.Value) (out []reflect.Value) {
+		own := state
+		own.rt = state.rt.fork()
+		own.posStack = make([]pos, 0, 8)
+		defer enterGIL(&own)()
+		out = make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.Zero(t.Out(i))
+		}
+		if hasError {
+			defer func() {
+				if r := recover(); r != nil {
+					e, ok := r.(Error)
+					if !ok {
+						panic(r)
+					}
+					var err error = e
+					out[len(out)-1] = reflect.ValueOf(&err).Elem()
+				}
+			}()
+		}
+		args := make([]Object, len(in))
+		for i, v := range in {
+			args[i] = ToObject(v.Interface())
+		}
+		res := fn.Call(args)
+		if outs == 1 {
+			out[0] = objectToValue(res, t.Out(0))
+		}
+		return out
+	})
+}
+
+// Converts obj to Go value of the type ptr points to and stores
+// it there. Returns an error if obj doesn't match the type.
+func FromObject(obj Object, ptr interface{}) (err error) {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("FromObject requires non-nil pointer, got %T", ptr)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			switch r.(type) {
+			case *EvalError:
+				err = errors.New(r.(*EvalError).msg)
+			default:
+				panic(r)
+			}
+		}
+	}()
+	v.Elem().Set(objectToValue(obj, v.Elem().Type()))
+	return nil
+}
@@ -0,0 +1,266 @@
+package core
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type marshalInner struct {
+	N int
+}
+
+type marshalStruct struct {
+	Name    string `joker:"name"`
+	Skipped int    `joker:"-"`
+	hidden  int
+	Tags    []string
+	Inner   *marshalInner
+}
+
+type marshalNode struct {
+	Next *marshalNode
+}
+
+func TestToObject(t *testing.T) {
+	in := NewInterpreter()
+	n := 5
+	var nilInner *marshalInner
+	var nilError error
+	shared := &marshalInner{N: 1}
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{marshalStruct{Name: "a", Skipped: 1, hidden: 2, Tags: []string{"x"}, Inner: &marshalInner{N: 3}},
+			`{:name "a" :Tags ["x"] :Inner {:N 3}}`},
+		{marshalStruct{}, `{:name "" :Tags nil :Inner nil}`},
+		{[]int{1, 2}, `[1 2]`},
+		{[2]string{"a", "b"}, `["a" "b"]`},
+		{[]interface{}{1, "a", nil, true}, `[1 "a" nil true]`},
+		{map[string]int{"a": 1, "b": 2}, `{"a" 1 "b" 2}`},
+		{map[int][]bool{1: {true}}, `{1 [true]}`},
+		{&n, `5`},
+		{nilInner, `nil`},
+		{nilError, `nil`},
+		{nil, `nil`},
+		{int8(-128), `-128`},
+		{uint8(255), `255`},
+		{uint64(math.MaxUint64), `18446744073709551615N`},
+		{float32(1.5), `1.5`},
+		{[]*marshalInner{shared, shared}, `[{:N 1} {:N 1}]`},
+	}
+	for _, test := range tests {
+		expected := evalString(t, in, "'"+test.expected)
+		if res := ToObject(test.value); !res.Equals(expected) {
+			t.Errorf("%#v: expected %s, got %s", test.value, test.expected, res.ToString(true))
+		}
+	}
+}
+
+func TestToObjectCycle(t *testing.T) {
+	node := &marshalNode{}
+	node.Next = node
+	list := []interface{}{nil}
+	list[0] = list
+	m := map[string]interface{}{}
+	m["self"] = m
+	for _, v := range []interface{}{node, list, m} {
+		func() {
+			defer func() {
+				r := recover()
+				if err, ok := r.(*EvalError); !ok || !strings.Contains(err.msg, "contains itself") {
+					t.Errorf("%T: expected error about cycle, got %v", v, r)
+				}
+			}()
+			ToObject(v)
+		}()
+	}
+}
+
+func TestFromObject(t *testing.T) {
+	in := NewInterpreter()
+	tests := []struct {
+		src      string
+		ptr      interface{}
+		expected interface{}
+	}{
+		{`{:name "a" :Skipped 1 :hidden 2 :Tags ["x" "y"] :Inner {:N 3}}`, new(marshalStruct),
+			marshalStruct{Name: "a", Tags: []string{"x", "y"}, Inner: &marshalInner{N: 3}}},
+		{`{"name" "b"}`, new(marshalStruct), marshalStruct{Name: "b"}},
+		{`[1 2 3]`, new([]int), []int{1, 2, 3}},
+		{`'(1 2)`, new([3]int), [3]int{1, 2, 0}},
+		{`{"a" 1 "b" 2}`, new(map[string]int), map[string]int{"a": 1, "b": 2}},
+		{`{:a [1 :b]}`, new(interface{}), map[interface{}]interface{}{"a": []interface{}{1, "b"}}},
+		{`5`, new(*int), func() *int { n := 5; return &n }()},
+		{`nil`, new(*int), (*int)(nil)},
+		{`nil`, new([]int), []int(nil)},
+		{`nil`, new(interface{}), nil},
+		{`127`, new(int8), int8(127)},
+		{`-128`, new(int8), int8(-128)},
+		{`255`, new(uint8), uint8(255)},
+		{`18446744073709551615N`, new(uint64), uint64(math.MaxUint64)},
+		{`9223372036854775807N`, new(int64), int64(math.MaxInt64)},
+		{`1`, new(float32), float32(1)},
+		{`1/2`, new(float64), 0.5},
+	}
+	for _, test := range tests {
+		if err := in.FromObject(evalString(t, in, test.src), test.ptr); err != nil {
+			t.Errorf("%s: %v", test.src, err)
+			continue
+		}
+		if res := reflect.ValueOf(test.ptr).Elem().Interface(); !reflect.DeepEqual(res, test.expected) {
+			t.Errorf("%s: expected %#v, got %#v", test.src, test.expected, res)
+		}
+	}
+}
+
+func TestFromObjectErrors(t *testing.T) {
+	in := NewInterpreter()
+	tests := []struct {
+		src string
+		ptr interface{}
+		msg string
+	}{
+		{`128`, new(int8), "Integer overflow: 128 doesn't fit into Go type int8"},
+		{`-129`, new(int8), "Integer overflow"},
+		{`-1`, new(uint8), "Integer overflow: -1 doesn't fit into Go type uint8"},
+		{`256`, new(uint8), "Integer overflow"},
+		{`-1`, new(uint64), "Integer overflow"},
+		{`18446744073709551616N`, new(uint64), "Integer overflow"},
+		{`9223372036854775808N`, new(int64), "Integer overflow"},
+		{`"1"`, new(int), "Cannot convert String to Go type int"},
+		{`[1 2 3]`, new([2]int), "too many elements"},
+		{`{:Tags "x"}`, new(marshalStruct), "Cannot convert Char to Go type string"},
+		{`nil`, new(int), "Cannot convert Nil to Go type int"},
+		{`(fn [] 1)`, new(func() (int, int)), "too many results"},
+	}
+	for _, test := range tests {
+		err := in.FromObject(evalString(t, in, test.src), test.ptr)
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.msg, err)
+		}
+	}
+	if err := FromObject(Int{I: 1}, marshalInner{}); err == nil {
+		t.Errorf("expected error for non-pointer")
+	}
+}
+
+func TestFuncToProc(t *testing.T) {
+	in := NewInterpreter()
+	in.RegisterNamespace("go", map[string]Proc{
+		"join": ToObject(func(sep string, xs ...int) string {
+			s := make([]string, len(xs))
+			for i, x := range xs {
+				s[i] = strconv.Itoa(x)
+			}
+			return strings.Join(s, sep)
+		}).(Proc),
+		"sqrt": ToObject(func(x float64) (float64, error) {
+			if x < 0 {
+				return 0, errors.New("negative argument")
+			}
+			return math.Sqrt(x), nil
+		}).(Proc),
+		"split": ToObject(func(s string) (string, string) {
+			return s[:1], s[1:]
+		}).(Proc),
+		"check": ToObject(func(ok bool) error {
+			if !ok {
+				return errors.New("check failed")
+			}
+			return nil
+		}).(Proc),
+		"inner": ToObject(func(m marshalInner) *marshalInner { return &m }).(Proc),
+	})
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(go/join "," 1 2 3)`, `"1,2,3"`},
+		{`(go/join ",")`, `""`},
+		{`(= 2.0 (go/sqrt 4))`, `true`},
+		{`(go/split "abc")`, `["a" "bc"]`},
+		{`(go/check true)`, `nil`},
+		{`(go/inner {:N 2})`, `{:N 2}`},
+		{`(try (go/sqrt -1) (catch Error e :caught))`, `:caught`},
+	}
+	for _, test := range tests {
+		if res := evalString(t, in, test.src).ToString(true); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expected, res)
+		}
+	}
+	errs := []struct {
+		src string
+		msg string
+	}{
+		{`(go/join)`, "Wrong number of args (0)"},
+		{`(go/join "," "a")`, "Cannot convert String to Go type int"},
+		{`(go/check false)`, "check failed"},
+		{`(go/sqrt -1)`, "negative argument"},
+	}
+	for _, test := range errs {
+		if _, err := in.EvalString(test.src); err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.msg, err)
+		}
+	}
+}
+
+func TestCallableToFunc(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `(def offset 10)`)
+	var add func(int) int
+	var parse func(string) (int, error)
+	var run func()
+	if err := in.FromObject(evalString(t, in, `(fn [x] (+ x offset))`), &add); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.FromObject(evalString(t, in, `(fn [s] (if (= s "") (throw (ex-info "empty" {})) (count s)))`), &parse); err != nil {
+		t.Fatal(err)
+	}
+	if err := in.FromObject(evalString(t, in, `(fn [] (throw (ex-info "boom" {})))`), &run); err != nil {
+		t.Fatal(err)
+	}
+	if res := add(1); res != 11 {
+		t.Errorf("expected 11, got %d", res)
+	}
+	if res, err := parse("abc"); res != 3 || err != nil {
+		t.Errorf("expected 3, nil, got %d, %v", res, err)
+	}
+	if res, err := parse(""); res != 0 || err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("expected 0 and error, got %d, %v", res, err)
+	}
+	func() {
+		defer func() {
+			if _, ok := recover().(Error); !ok {
+				t.Errorf("expected function without error result to panic")
+			}
+		}()
+		run()
+	}()
+	// Called from several goroutines at once, as well as
+	// from Joker code (holding the interpreter lock).
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if res := add(i); res != i+10 {
+				t.Errorf("expected %d, got %d", i+10, res)
+			}
+		}(i)
+	}
+	wg.Wait()
+	in.RegisterNamespace("go", map[string]Proc{
+		"call-add": func(args []Object) Object {
+			return Int{I: add(EnsureInt(args, 0).I)}
+		},
+	})
+	if res := evalString(t, in, `(go/call-add 5)`).ToString(false); res != "15" {
+		t.Errorf("expected 15, got %s", res)
+	}
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"fmt"
+	"io"
+)
+
+type (
+	FnMetrics struct {
+		Name       string `json:"name"`
+		File       string `json:"file"`
+		Line       int    `json:"line"`
+		Column     int    `json:"column"`
+		Lines      int    `json:"lines"`
+		Depth      int    `json:"depth"`
+		Branches   int    `json:"branches"`
+		Complexity int    `json:"complexity"`
+		Arities    int    `json:"arities"`
+	}
+	MetricsThresholds struct {
+		MaxLines      int
+		MaxDepth      int
+		MaxComplexity int
+		MaxArities    int
+	}
+)
+
+var conditionalForms = map[string]bool{
+	"if":        true,
+	"if-not":    true,
+	"if-let":    true,
+	"if-some":   true,
+	"when":      true,
+	"when-not":  true,
+	"when-let":  true,
+	"when-some": true,
+}
+
+func isDefn(obj Object) bool {
+	seq, ok := obj.(Seq)
+	if !ok || seq.IsEmpty() {
+		return false
+	}
+	sym, ok := seq.First().(Symbol)
+	if !ok || sym.ns != nil {
+		return false
+	}
+	return *sym.name == "defn" || *sym.name == "defn-"
+}
+
+// Returns the number of branches introduced by a form if it
+// is one of the conditional forms and 0 otherwise.
+// cond contributes a branch per test/expression pair (except :else),
+// case contributes a branch per clause (except the default one).
+func formBranches(seq Seq) int {
+	sym, ok := seq.First().(Symbol)
+	if !ok || sym.ns != nil {
+		return 0
+	}
+	switch name := *sym.name; {
+	case conditionalForms[name]:
+		return 1
+	case name == "cond":
+		res := 0
+		for clauses := seq.Rest(); !clauses.IsEmpty(); clauses = clauses.Rest().Rest() {
+			if !clauses.First().Equals(MakeKeyword("else")) {
+				res++
+			}
+		}
+		return res
+	case name == "case":
+		return (SeqCount(seq) - 2) / 2
+	}
+	return 0
+}
+
+func measureForm(obj Object, depth int, m *FnMetrics) {
+	var children []Object
+	switch obj := obj.(type) {
+	case Seq:
+		if !obj.IsEmpty() {
+			m.Branches += formBranches(obj)
+		}
+		children = ToSlice(obj)
+	case *Vector:
+		children = ToSlice(obj.Seq())
+	case Map:
+		for iter := obj.Iter(); iter.HasNext(); {
+			p := iter.Next()
+			children = append(children, p.key, p.value)
+		}
+	case *MapSet:
+		children = ToSlice(obj.Seq())
+	default:
+		return
+	}
+	if depth > m.Depth {
+		m.Depth = depth
+	}
+	for _, child := range children {
+		measureForm(child, depth+1, m)
+	}
+}
+
+func countArities(seq Seq) int {
+	res := 0
+	for ; !seq.IsEmpty(); seq = seq.Rest() {
+		switch seq.First().(type) {
+		case *Vector:
+			return 1
+		case Seq:
+			res++
+		}
+	}
+	return res
+}
+
+func measureDefn(seq Seq) FnMetrics {
+	pos := GetPosition(seq)
+	res := FnMetrics{
+		File:   pos.Filename(),
+		Line:   pos.startLine,
+		Column: pos.startColumn,
+		Lines:  pos.endLine - pos.startLine + 1,
+	}
+	rest := seq.Rest()
+	res.Name = rest.First().ToString(false)
+	rest = rest.Rest()
+	res.Arities = countArities(rest)
+	for ; !rest.IsEmpty(); rest = rest.Rest() {
+		measureForm(rest.First(), 1, &res)
+	}
+	res.Complexity = res.Branches + 1
+	return res
+}
+
+// Reads all top level forms from reader and returns metrics
+// for those of them that are defn forms.
+func CollectMetrics(reader *Reader) ([]FnMetrics, error) {
+	var res []FnMetrics
+	for {
+		obj, err := TryRead(reader)
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return res, err
+		}
+		if isDefn(obj) {
+			res = append(res, measureDefn(obj.(Seq)))
+		}
+	}
+}
+
+func (m *FnMetrics) warnIfExceeds(value int, max int, what string) bool {
+	if max > 0 && value > max {
+		pos := Position{
+			filename:    STRINGS.Intern(m.File),
+			startLine:   m.Line,
+			startColumn: m.Column,
+		}
+		printParseWarning(pos, fmt.Sprintf("%s %s is %d (max %d)", m.Name, what, value, max))
+		return true
+	}
+	return false
+}
+
+// Prints a warning for every metric that exceeds its threshold.
+// Zero thresholds are ignored. Returns true if any of the
+// thresholds is exceeded.
+func (m *FnMetrics) WarnOnThresholds(t *MetricsThresholds) bool {
+	res := m.warnIfExceeds(m.Lines, t.MaxLines, "line count")
+	res = m.warnIfExceeds(m.Depth, t.MaxDepth, "nesting depth") || res
+	res = m.warnIfExceeds(m.Complexity, t.MaxComplexity, "complexity") || res
+	res = m.warnIfExceeds(m.Arities, t.MaxArities, "arity count") || res
+	return res
+}
@@ -0,0 +1,7 @@
+package core
+
+// Name of the function native extensions must export.
+// It is called with the environment the extension is loaded into
+// and is expected to add namespaces to it (see Env.EnsureNamespace).
+// Its type must be either func(*core.Env) or func(*core.Env) error.
+const NATIVE_INIT = "JokerInit"
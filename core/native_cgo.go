@@ -0,0 +1,33 @@
+//go:build cgo
+// +build cgo
+
+package core
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// Loads Go plugin (built with -buildmode=plugin against the same
+// version of Joker) and lets it register its namespaces in the current
+// environment. Go supports plugins only on some platforms (Linux and macOS)
+// and only in binaries built with cgo.
+func LoadNative(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	sym, err := p.Lookup(NATIVE_INIT)
+	if err != nil {
+		return err
+	}
+	switch init := sym.(type) {
+	case func(*Env):
+		init(GLOBAL_ENV)
+		return nil
+	case func(*Env) error:
+		return init(GLOBAL_ENV)
+	default:
+		return fmt.Errorf("%s in %s must have type func(*core.Env) error, got %T", NATIVE_INIT, path, sym)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !cgo
+// +build !cgo
+
+package core
+
+import (
+	"fmt"
+)
+
+// Go plugins require cgo, so binaries built without it
+// (e.g. cross-compiled with build-all.sh) can't load native extensions.
+func LoadNative(path string) error {
+	return fmt.Errorf("Can't load native extension %s: this build of Joker doesn't support native extensions (it was built with CGO_ENABLED=0)", path)
+}
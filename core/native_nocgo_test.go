@@ -0,0 +1,16 @@
+//go:build !cgo
+// +build !cgo
+
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadNativeWithoutCgo(t *testing.T) {
+	err := LoadNative("ext.so")
+	if err == nil || !strings.Contains(err.Error(), "CGO_ENABLED=0") {
+		t.Errorf("expected error about missing cgo support, got %v", err)
+	}
+}
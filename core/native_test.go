@@ -0,0 +1,15 @@
+package core
+
+import (
+	"testing"
+)
+
+func TestLoadNativeMissingFile(t *testing.T) {
+	in := NewInterpreter()
+	if err := in.LoadNative("does-not-exist.so"); err == nil {
+		t.Errorf("expected error loading missing extension")
+	}
+	if _, err := in.EvalString(`(load-native "does-not-exist.so")`); err == nil {
+		t.Errorf("expected load-native to throw")
+	}
+}
@@ -63,6 +63,28 @@ func NewNamespace(sym Symbol) *Namespace {
 	}
 }
 
+// Returns a copy of ns with copies of the vars interned in it.
+// vars maps original vars to their copies, so that the caller can
+// fix up references to them from other namespaces.
+func (ns *Namespace) clone(vars map[*Var]*Var) *Namespace {
+	res := NewNamespace(ns.Name)
+	res.meta = ns.meta
+	res.isUsed = ns.isUsed
+	for name, vr := range ns.mappings {
+		if vr.ns == ns {
+			c := *vr
+			c.ns = res
+			vars[vr] = &c
+			vr = &c
+		}
+		res.mappings[name] = vr
+	}
+	for name, alias := range ns.aliases {
+		res.aliases[name] = alias
+	}
+	return res
+}
+
 func (ns *Namespace) Refer(sym Symbol, vr *Var) *Var {
 	if sym.ns != nil {
 		panic(RT.NewError("Can't intern namespace-qualified symbol " + sym.ToString(false)))
@@ -1,6 +1,6 @@
 //go:generate go run gen_data/gen_data.go
-//go:generate go run gen/gen_types.go assert Comparable *Vector Char String Symbol Keyword Regex Bool Number Seqable Callable *Type Meta Int Stack Map Set Associative Reversible Named Comparator *Ratio *Namespace *Var Error *Fn Deref *Atom Ref KVReduce Pending
-//go:generate go run gen/gen_types.go info *List *ArrayMapSeq *ArrayMap *HashMap *ExInfo *Fn *Var Nil *Ratio *BigInt *BigFloat Char Double Int Bool Keyword Regex Symbol String *LazySeq *MappingSeq *ArraySeq *ConsSeq *NodeSeq *ArrayNodeSeq *MapSet *Vector *VectorSeq *VectorRSeq
+//go:generate go run gen/gen_types.go assert Comparable *Vector Char String Symbol Keyword Regex Bool Number Seqable Callable *Type Meta Int Stack Map Set Associative Reversible Named Comparator *Ratio *Namespace *Var Error *Fn Deref *Atom Ref KVReduce Pending Time UUID BlockingDeref *Promise *Channel Watchable *Protocol
+//go:generate go run gen/gen_types.go info *List *ArrayMapSeq *ArrayMap *HashMap *ExInfo *Fn *Var Nil *Ratio *BigInt *BigFloat Char Double Int Bool Keyword Regex Symbol String *LazySeq *MappingSeq *ArraySeq *ConsSeq *NodeSeq *ArrayNodeSeq *MapSet *Vector *VectorSeq *VectorRSeq Time UUID
 
 package core
 
@@ -34,6 +34,12 @@ type (
 	Type struct {
 		name        string
 		reflectType reflect.Type
+		// Fields of types defined with deftype or defrecord,
+		// nil for built-in types.
+		fields []Keyword
+		// Implementations of Object methods (toString, equals
+		// and hashCode) given to deftype or defrecord.
+		objectMethods Map
 	}
 	Object interface {
 		Equality
@@ -65,6 +71,18 @@ type (
 	MetaHolder struct {
 		meta Map
 	}
+	// Watches and validator of a reference (atom or var).
+	WatchHolder struct {
+		watches   Map
+		validator Callable
+	}
+	Watchable interface {
+		Deref
+		AddWatch(key Object, fn Callable)
+		RemoveWatch(key Object)
+		SetValidator(fn Callable)
+		GetValidator() Callable
+	}
 	ObjectInfo struct {
 		Position
 	}
@@ -127,11 +145,18 @@ type (
 	Var struct {
 		InfoHolder
 		MetaHolder
+		WatchHolder
 		ns      *Namespace
 		name    Symbol
 		Value   Object
 		expr    Expr
 		isMacro bool
+		// Interned by linter for a symbol it could not resolve.
+		isFake bool
+		// Set once the var is bound with push-thread-bindings,
+		// so that resolving other vars doesn't have to
+		// look at binding stack.
+		threadBound bool
 	}
 	Proc func([]Object) Object
 	Fn   struct {
@@ -200,7 +225,11 @@ type (
 	}
 	Atom struct {
 		MetaHolder
+		WatchHolder
 		value Object
+		// Incremented on every change of value, so that swap
+		// can tell if the atom was changed while f was running.
+		version int
 	}
 	Deref interface {
 		Deref() Object
@@ -232,6 +261,7 @@ func regInterface(name string, inst interface{}) {
 
 func init() {
 	regInterface("Associative", (*Associative)(nil))
+	regInterface("BlockingDeref", (*BlockingDeref)(nil))
 	regInterface("Callable", (*Callable)(nil))
 	regInterface("Collection", (*Collection)(nil))
 	regInterface("Comparable", (*Comparable)(nil))
@@ -244,6 +274,7 @@ func init() {
 	regInterface("Map", (*Map)(nil))
 	regInterface("Named", (*Named)(nil))
 	regInterface("Number", (*Number)(nil))
+	regInterface("Object", (*Object)(nil))
 	regInterface("Pending", (*Pending)(nil))
 	regInterface("Ref", (*Ref)(nil))
 	regInterface("Reversible", (*Reversible)(nil))
@@ -252,6 +283,7 @@ func init() {
 	regInterface("Sequential", (*Sequential)(nil))
 	regInterface("Set", (*Set)(nil))
 	regInterface("Stack", (*Stack)(nil))
+	regInterface("Watchable", (*Watchable)(nil))
 
 	regRefType("ArrayMap", (*ArrayMap)(nil))
 	regRefType("ArrayMapSeq", (*ArrayMapSeq)(nil))
@@ -263,6 +295,7 @@ func init() {
 	regRefType("BigInt", (*BigInt)(nil))
 	regType("Bool", (*Bool)(nil))
 	regRefType("Buffer", (*Buffer)(nil))
+	regRefType("Channel", (*Channel)(nil))
 	regType("Char", (*Char)(nil))
 	regRefType("ConsSeq", (*ConsSeq)(nil))
 	regRefType("Delay", (*Delay)(nil))
@@ -271,6 +304,7 @@ func init() {
 	regRefType("ExInfo", (*ExInfo)(nil))
 	regRefType("Fn", (*Fn)(nil))
 	regRefType("File", (*File)(nil))
+	regRefType("Future", (*Future)(nil))
 	regRefType("HashMap", (*HashMap)(nil))
 	regType("Int", (*Int)(nil))
 	regType("Keyword", (*Keyword)(nil))
@@ -282,12 +316,17 @@ func init() {
 	regRefType("NodeSeq", (*NodeSeq)(nil))
 	regRefType("ParseError", (*ParseError)(nil))
 	regRefType("Proc", (*Proc)(nil))
+	regRefType("Promise", (*Promise)(nil))
+	regRefType("Protocol", (*Protocol)(nil))
 	regRefType("Ratio", (*Ratio)(nil))
+	regRefType("Reified", (*Reified)(nil))
 	regRefType("RecurBindings", (*RecurBindings)(nil))
 	regType("Regex", (*Regex)(nil))
 	regType("String", (*String)(nil))
 	regType("Symbol", (*Symbol)(nil))
+	regType("Time", (*Time)(nil))
 	regRefType("Type", (*Type)(nil))
+	regType("UUID", (*UUID)(nil))
 	regRefType("Var", (*Var)(nil))
 	regRefType("Vector", (*Vector)(nil))
 	regRefType("VectorRSeq", (*VectorRSeq)(nil))
@@ -301,6 +340,22 @@ func (pos Position) Filename() string {
 	return *pos.filename
 }
 
+func (pos Position) Line() int {
+	return pos.startLine
+}
+
+func (pos Position) Column() int {
+	return pos.startColumn
+}
+
+func (pos Position) EndLine() int {
+	return pos.endLine
+}
+
+func (pos Position) EndColumn() int {
+	return pos.endColumn
+}
+
 var hasher hash.Hash32 = fnv.New32a()
 
 func newIteratorError() error {
@@ -454,6 +509,51 @@ func (a *Atom) Deref() Object {
 	return a.value
 }
 
+func (a *Atom) set(value Object) Object {
+	validate(a.validator, value)
+	oldValue := a.value
+	a.value = value
+	a.version++
+	a.notifyWatches(a, oldValue, value)
+	return oldValue
+}
+
+// Sets the value of the atom to value if its current value
+// is identical to oldValue. Returns true if the value was set.
+// Objects that are not pointers (numbers, strings, keywords, etc.)
+// carry their reader positions, so they are compared with Equals.
+func (a *Atom) CompareAndSet(oldValue Object, value Object) bool {
+	if reflect.TypeOf(oldValue).Kind() == reflect.Ptr {
+		if a.value != oldValue {
+			return false
+		}
+	} else if !a.value.Equals(oldValue) {
+		return false
+	}
+	a.set(value)
+	return true
+}
+
+// Sets the value of the atom to (apply f current-value args).
+// f may be called several times, since other goroutines can change
+// the atom while it's running (e.g. if it waits for a channel).
+// Returns old and new values.
+func (a *Atom) Swap(f Callable, args []Object) (Object, Object) {
+	for {
+		oldValue, version := a.value, a.version
+		value := f.Call(append([]Object{oldValue}, args...))
+		if a.version == version {
+			a.set(value)
+			return oldValue, value
+		}
+	}
+}
+
+// Sets the value of the atom and returns the old value.
+func (a *Atom) Reset(value Object) Object {
+	return a.set(value)
+}
+
 func (d *Delay) ToString(escape bool) string {
 	return "#object[Delay]"
 }
@@ -690,6 +790,45 @@ func AlterMeta(m *MetaHolder, fn *Fn, args []Object) Map {
 	return m.meta
 }
 
+func (w *WatchHolder) AddWatch(key Object, fn Callable) {
+	if w.watches == nil {
+		w.watches = EmptyArrayMap()
+	}
+	w.watches = w.watches.Assoc(key, fn.(Object)).(Map)
+}
+
+func (w *WatchHolder) RemoveWatch(key Object) {
+	if w.watches != nil {
+		w.watches = w.watches.Without(key)
+	}
+}
+
+func (w *WatchHolder) SetValidator(fn Callable) {
+	w.validator = fn
+}
+
+func (w *WatchHolder) GetValidator() Callable {
+	return w.validator
+}
+
+func validate(validator Callable, value Object) {
+	if validator != nil && !toBool(validator.Call([]Object{value})) {
+		panic(RT.NewError("Invalid reference state"))
+	}
+}
+
+// Calls every watch fn with the key it was added with,
+// the reference and its old and new values.
+func (w *WatchHolder) notifyWatches(ref Object, oldValue Object, newValue Object) {
+	if w.watches == nil {
+		return
+	}
+	for iter := w.watches.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		p.value.(Callable).Call([]Object{p.key, ref, oldValue, newValue})
+	}
+}
+
 func (sym Symbol) WithMeta(meta Map) Object {
 	res := sym
 	res.meta = SafeMerge(res.meta, meta)
@@ -728,7 +867,14 @@ func (v *Var) Hash() uint32 {
 	return hashPtr(uintptr(unsafe.Pointer(v)))
 }
 
+// Returns the value of the var: its thread-local binding
+// (see binding.go) if there is one, root value otherwise.
 func (v *Var) Resolve() Object {
+	if v.threadBound {
+		if b := RT.binding(v); b != nil {
+			return b.value
+		}
+	}
 	if v.Value == nil {
 		panic(RT.NewError("Unbound var: " + v.ToString(false)))
 	}
@@ -746,6 +892,19 @@ func (v *Var) Deref() Object {
 	return v.Resolve()
 }
 
+// Changes the root value of the var, checking it with
+// the validator and notifying the watches. Thread-local
+// bindings are not affected.
+func (v *Var) BindRoot(value Object) {
+	validate(v.validator, value)
+	oldValue := v.Value
+	v.Value = value
+	if oldValue == nil {
+		oldValue = NIL
+	}
+	v.notifyWatches(v, oldValue, value)
+}
+
 func (n Nil) ToString(escape bool) string {
 	return "nil"
 }
@@ -972,6 +1131,16 @@ func MakeDouble(d float64) Double {
 }
 
 func (d Double) ToString(escape bool) string {
+	if escape {
+		switch {
+		case math.IsInf(d.D, 1):
+			return "##Inf"
+		case math.IsInf(d.D, -1):
+			return "##-Inf"
+		case math.IsNaN(d.D):
+			return "##NaN"
+		}
+	}
 	return fmt.Sprintf("%f", d.D)
 }
 
@@ -1129,6 +1298,11 @@ func (k Keyword) Call(args []Object) Object {
 		if ok {
 			return v
 		}
+	case *TypeInstance:
+		ok, v := m.Get(k)
+		if ok {
+			return v
+		}
 	}
 	if len(args) == 2 {
 		return args[1]
@@ -1319,6 +1493,9 @@ func IsInstance(t *Type, obj Object) bool {
 	if obj.Equals(NIL) {
 		return false
 	}
+	if t.fields != nil {
+		return obj.GetType() == t
+	}
 	if t.reflectType.Kind() == reflect.Interface {
 		return obj.GetType().reflectType.Implements(t.reflectType)
 	} else {
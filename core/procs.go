@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -248,6 +249,39 @@ var procRegex Proc = func(args []Object) Object {
 	return Regex{R: r}
 }
 
+var procInst Proc = func(args []Object) Object {
+	t, err := ParseTime(EnsureString(args, 0).S)
+	if err != nil {
+		panic(RT.NewError(err.Error()))
+	}
+	return t
+}
+
+var procInstMs Proc = func(args []Object) Object {
+	t := EnsureTime(args, 0)
+	return Int{I: int(t.T.UnixNano() / int64(time.Millisecond))}
+}
+
+var procUUID Proc = func(args []Object) Object {
+	u, err := ParseUUID(EnsureString(args, 0).S)
+	if err != nil {
+		panic(RT.NewError(err.Error()))
+	}
+	return u
+}
+
+var procParseUUID Proc = func(args []Object) Object {
+	u, err := ParseUUID(EnsureString(args, 0).S)
+	if err != nil {
+		return NIL
+	}
+	return u
+}
+
+var procRandomUUID Proc = func(args []Object) Object {
+	return RandomUUID()
+}
+
 var procReSeq Proc = func(args []Object) Object {
 	re := EnsureRegex(args, 0)
 	s := EnsureString(args, 1)
@@ -314,7 +348,7 @@ var procIntern = func(args []Object) Object {
 	sym := EnsureSymbol(args, 1)
 	vr := ns.Intern(sym)
 	if len(args) == 3 {
-		vr.Value = args[2]
+		vr.BindRoot(args[2])
 	}
 	return vr
 }
@@ -335,26 +369,136 @@ var procAtom = func(args []Object) Object {
 		if ok, v := m.Get(MakeKeyword("meta")); ok {
 			res.meta = AssertMap(v, "")
 		}
+		if ok, v := m.Get(MakeKeyword("validator")); ok && !v.Equals(NIL) {
+			res.validator = AssertCallable(v, "")
+			validate(res.validator, res.value)
+		}
 	}
 	return res
 }
 
 var procDeref = func(args []Object) Object {
+	if len(args) == 3 {
+		ms := EnsureInt(args, 1).I
+		return EnsureBlockingDeref(args, 0).DerefWithTimeout(time.Duration(ms)*time.Millisecond, args[2])
+	}
 	return EnsureDeref(args, 0).Deref()
 }
 
+var procFutureCall Proc = func(args []Object) Object {
+	return MakeFuture(EnsureCallable(args, 0))
+}
+
+var procPromise Proc = func(args []Object) Object {
+	return MakePromise()
+}
+
+var procDeliver Proc = func(args []Object) Object {
+	p := EnsurePromise(args, 0)
+	if p.Deliver(args[1]) {
+		return p
+	}
+	return NIL
+}
+
+var procCpuCount Proc = func(args []Object) Object {
+	return Int{I: runtime.NumCPU()}
+}
+
+var procChan Proc = func(args []Object) Object {
+	size := EnsureInt(args, 0).I
+	switch policy := EnsureKeyword(args, 1); policy.ToString(false) {
+	case ":fixed":
+		return MakeChannel(size, FIXED_BUFFER)
+	case ":dropping":
+		return MakeChannel(size, DROPPING_BUFFER)
+	case ":sliding":
+		return MakeChannel(size, SLIDING_BUFFER)
+	default:
+		panic(RT.NewError("Unknown buffer type: " + policy.ToString(false)))
+	}
+}
+
+var procPut Proc = func(args []Object) Object {
+	return Bool{B: EnsureChannel(args, 0).Put(args[1])}
+}
+
+var procTake Proc = func(args []Object) Object {
+	return EnsureChannel(args, 0).Take()
+}
+
+var procClose Proc = func(args []Object) Object {
+	EnsureChannel(args, 0).Close()
+	return NIL
+}
+
+var procAlts Proc = func(args []Object) Object {
+	ports := ToSlice(EnsureSeqable(args, 0).Seq())
+	opts := EnsureMap(args, 1)
+	hasDefault, defaultValue := opts.Get(MakeKeyword("default"))
+	hasPriority, priority := opts.Get(MakeKeyword("priority"))
+	return Alts(ports, hasDefault, defaultValue, hasPriority && toBool(priority))
+}
+
+var procTimeout Proc = func(args []Object) Object {
+	ms := EnsureInt(args, 0).I
+	return MakeTimeoutChannel(time.Duration(ms) * time.Millisecond)
+}
+
+var procThreadCall Proc = func(args []Object) Object {
+	return ChannelCall(EnsureCallable(args, 0))
+}
+
 var procSwap = func(args []Object) Object {
-	a := EnsureAtom(args, 0)
-	f := EnsureCallable(args, 1)
-	fargs := append([]Object{a.value}, args[2:]...)
-	a.value = f.Call(fargs)
-	return a.value
+	_, res := EnsureAtom(args, 0).Swap(EnsureCallable(args, 1), args[2:])
+	return res
+}
+
+var procSwapVals = func(args []Object) Object {
+	oldValue, res := EnsureAtom(args, 0).Swap(EnsureCallable(args, 1), args[2:])
+	return NewVectorFrom(oldValue, res)
 }
 
 var procReset = func(args []Object) Object {
-	a := EnsureAtom(args, 0)
-	a.value = args[1]
-	return a.value
+	EnsureAtom(args, 0).Reset(args[1])
+	return args[1]
+}
+
+var procResetVals = func(args []Object) Object {
+	oldValue := EnsureAtom(args, 0).Reset(args[1])
+	return NewVectorFrom(oldValue, args[1])
+}
+
+var procCompareAndSet = func(args []Object) Object {
+	return Bool{B: EnsureAtom(args, 0).CompareAndSet(args[1], args[2])}
+}
+
+var procAddWatch = func(args []Object) Object {
+	EnsureWatchable(args, 0).AddWatch(args[1], EnsureCallable(args, 2))
+	return args[0]
+}
+
+var procRemoveWatch = func(args []Object) Object {
+	EnsureWatchable(args, 0).RemoveWatch(args[1])
+	return args[0]
+}
+
+var procSetValidator = func(args []Object) Object {
+	r := EnsureWatchable(args, 0)
+	var fn Callable
+	if !args[1].Equals(NIL) {
+		fn = EnsureCallable(args, 1)
+		validate(fn, r.Deref())
+	}
+	r.SetValidator(fn)
+	return NIL
+}
+
+var procGetValidator = func(args []Object) Object {
+	if fn := EnsureWatchable(args, 0).GetValidator(); fn != nil {
+		return fn.(Object)
+	}
+	return NIL
 }
 
 var procAlterMeta = func(args []Object) Object {
@@ -380,7 +524,11 @@ var procEmpty = func(args []Object) Object {
 
 var procIsBound = func(args []Object) Object {
 	vr := EnsureVar(args, 0)
-	return Bool{B: vr.Value != nil}
+	return Bool{B: vr.Value != nil || RT.binding(vr) != nil}
+}
+
+var procIsThreadBound = func(args []Object) Object {
+	return Bool{B: RT.binding(EnsureVar(args, 0)) != nil}
 }
 
 func toNative(obj Object) interface{} {
@@ -902,7 +1050,7 @@ var procType Proc = func(args []Object) Object {
 }
 
 func printObject(obj Object, w io.Writer) {
-	printReadably := toBool(GLOBAL_ENV.printReadably.Value)
+	printReadably := toBool(GLOBAL_ENV.printReadably.Resolve())
 	switch obj := obj.(type) {
 	case Printer:
 		obj.Print(w, printReadably)
@@ -914,7 +1062,7 @@ func printObject(obj Object, w io.Writer) {
 var procPr Proc = func(args []Object) Object {
 	n := len(args)
 	if n > 0 {
-		f := AssertIOWriter(GLOBAL_ENV.stdout.Value, "")
+		f := AssertIOWriter(GLOBAL_ENV.stdout.Resolve(), "")
 		for _, arg := range args[:n-1] {
 			printObject(arg, f)
 			fmt.Fprint(f, " ")
@@ -925,7 +1073,7 @@ var procPr Proc = func(args []Object) Object {
 }
 
 var procNewline Proc = func(args []Object) Object {
-	f := AssertIOWriter(GLOBAL_ENV.stdout.Value, "")
+	f := AssertIOWriter(GLOBAL_ENV.stdout.Resolve(), "")
 	fmt.Fprintln(f)
 	return NIL
 }
@@ -989,6 +1137,16 @@ func AssertIOWriter(obj Object, msg string) io.Writer {
 	}
 }
 
+// Returns argument at index, which must be an integer between min
+// and max (inclusive), the range of Go type goType it's converted to.
+func EnsureIntInRange(args []Object, index int, min int64, max int64, goType string) int64 {
+	i := int64(EnsureInt(args, index).I)
+	if i < min || i > max {
+		panic(RT.NewError(fmt.Sprintf("Integer overflow: %d doesn't fit into Go type %s", i, goType)))
+	}
+	return i
+}
+
 var procRead Proc = func(args []Object) Object {
 	f := EnsureIOReader(args, 0)
 	return readFromReader(bufio.NewReader(f))
@@ -1002,7 +1160,7 @@ var procReadString Proc = func(args []Object) Object {
 var procReadLine Proc = func(args []Object) Object {
 	CheckArity(args, 0, 0)
 	var line string
-	f := AssertIOReader(GLOBAL_ENV.stdin.Value, "")
+	f := AssertIOReader(GLOBAL_ENV.stdin.Resolve(), "")
 	fmt.Fscanln(f, &line)
 	return String{S: line}
 }
@@ -1150,15 +1308,117 @@ var procVarGet Proc = func(args []Object) Object {
 }
 
 var procVarSet Proc = func(args []Object) Object {
-	EnsureVar(args, 0).Value = args[1]
+	vr := EnsureVar(args, 0)
+	vr.set(args[1])
 	return args[1]
 }
 
+var procAlterVarRoot Proc = func(args []Object) Object {
+	vr := EnsureVar(args, 0)
+	f := EnsureCallable(args, 1)
+	root := vr.Value
+	if root == nil {
+		root = NIL
+	}
+	vr.BindRoot(f.Call(append([]Object{root}, args[2:]...)))
+	return vr.Value
+}
+
+var procPushThreadBindings Proc = func(args []Object) Object {
+	RT.PushBindings(EnsureMap(args, 0))
+	return NIL
+}
+
+var procPopThreadBindings Proc = func(args []Object) Object {
+	RT.PopBindings()
+	return NIL
+}
+
+var procGetThreadBindings Proc = func(args []Object) Object {
+	return RT.GetBindings()
+}
+
+var procProtocol Proc = func(args []Object) Object {
+	name := EnsureSymbol(args, 0)
+	var methods []string
+	for iter := iter(EnsureSeqable(args, 1).Seq()); iter.HasNext(); {
+		methods = append(methods, AssertSymbol(iter.Next(), "Protocol method name must be a symbol").Name())
+	}
+	return MakeProtocol(name, methods)
+}
+
+var procProtocolMethod Proc = func(args []Object) Object {
+	return EnsureProtocol(args, 0).Method(EnsureSymbol(args, 1).Name())
+}
+
+// nil stands for the type of nil in extend and extends?
+func ensureExtendedType(args []Object, index int) *Type {
+	if args[index].Equals(NIL) {
+		return TYPES["Nil"]
+	}
+	return EnsureType(args, index)
+}
+
+var procExtend Proc = func(args []Object) Object {
+	EnsureProtocol(args, 1).Extend(ensureExtendedType(args, 0), EnsureMap(args, 2))
+	return NIL
+}
+
+var procExtends Proc = func(args []Object) Object {
+	return Bool{B: EnsureProtocol(args, 0).Extends(ensureExtendedType(args, 1))}
+}
+
+var procExtenders Proc = func(args []Object) Object {
+	return EnsureProtocol(args, 0).Extenders()
+}
+
+var procSatisfies Proc = func(args []Object) Object {
+	return Bool{B: EnsureProtocol(args, 0).Satisfies(args[1])}
+}
+
+var procReify Proc = func(args []Object) Object {
+	return MakeReified(args)
+}
+
+var procDeftype Proc = func(args []Object) Object {
+	v, ok := args[1].(*Vector)
+	if !ok {
+		panic(RT.NewError("Fields must be a vector, got " + args[1].ToString(true)))
+	}
+	var fields []Keyword
+	for iter := iter(v.Seq()); iter.HasNext(); {
+		fields = append(fields, MakeKeyword(AssertSymbol(iter.Next(), "Field name must be a symbol").Name()))
+	}
+	return DefineType(EnsureSymbol(args, 0).ToString(false), fields, EnsureBool(args, 2).B)
+}
+
+var procNewInstance Proc = func(args []Object) Object {
+	return EnsureType(args, 0).NewInstance(args[1:])
+}
+
+var procMapToRecord Proc = func(args []Object) Object {
+	return EnsureType(args, 0).NewRecord(EnsureMap(args, 1))
+}
+
+var procSetObjectMethods Proc = func(args []Object) Object {
+	t := EnsureType(args, 0)
+	if t.fields == nil {
+		panic(RT.NewError("Can't define Object methods of built-in type " + t.name))
+	}
+	t.objectMethods = EnsureMap(args, 1)
+	return NIL
+}
+
+var procIsRecord Proc = func(args []Object) Object {
+	_, ok := args[0].(*Record)
+	return Bool{B: ok}
+}
+
 var procNsResolve Proc = func(args []Object) Object {
 	ns := EnsureNamespace(args, 0)
 	sym := EnsureSymbol(args, 1)
-	if sym.ns == nil && TYPES[*sym.name] != nil {
-		return TYPES[*sym.name]
+	if t := GLOBAL_ENV.FindType(*sym.name); sym.ns == nil && t != nil {
+		return t
 	}
 	if vr, ok := GLOBAL_ENV.ResolveIn(ns, sym); ok {
 		return vr
@@ -1231,6 +1491,14 @@ var procLoadFile Proc = func(args []Object) Object {
 	return NIL
 }
 
+var procLoadNative Proc = func(args []Object) Object {
+	path := EnsureString(args, 0)
+	if err := LoadNative(path.S); err != nil {
+		panic(RT.NewError(err.Error()))
+	}
+	return NIL
+}
+
 var procReduceKv Proc = func(args []Object) Object {
 	f := EnsureCallable(args, 0)
 	init := args[1]
@@ -1252,14 +1520,14 @@ var procIndexOf Proc = func(args []Object) Object {
 var procLibPath Proc = func(args []Object) Object {
 	sym := EnsureSymbol(args, 0)
 	var file string
-	if GLOBAL_ENV.file.Value == nil {
+	if f, ok := GLOBAL_ENV.file.get().(String); ok {
+		file = f.S
+	} else {
 		var err error
 		file, err = filepath.Abs("user")
 		if err != nil {
 			panic(RT.NewError(err.Error()))
 		}
-	} else {
-		file = AssertString(GLOBAL_ENV.file.Value, "").S
 	}
 	ns := GLOBAL_ENV.CurrentNamespace().Name
 	parts := strings.Split(ns.Name(), ".")
@@ -1280,39 +1548,58 @@ var procInternFakeVar Proc = func(args []Object) Object {
 func ProcessReader(reader *Reader, filename string, phase Phase) error {
 	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
 	if filename != "" {
-		currentFilename := parseContext.GlobalEnv.file.Value
+		currentFilename := parseContext.GlobalEnv.file.get()
 		defer func() {
-			parseContext.GlobalEnv.file.Value = currentFilename
+			if currentFilename != nil {
+				parseContext.GlobalEnv.file.set(currentFilename)
+			} else {
+				parseContext.GlobalEnv.file.Value = nil
+			}
 		}()
 		s, err := filepath.Abs(filename)
 		if err != nil {
 			panic(RT.NewError(err.Error()))
 		}
-		parseContext.GlobalEnv.file.Value = String{S: s}
+		parseContext.GlobalEnv.file.set(String{S: s})
 	}
+	var readErr error
 	for {
 		obj, err := TryRead(reader)
 		if err == io.EOF {
-			return nil
+			return readErr
 		}
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			return err
+			if LINTER_MODE && reader.unclosed != nil {
+				PrintError(GLOBAL_ENV.errWriter(), reader.unclosed)
+			}
+			PrintError(GLOBAL_ENV.errWriter(), err)
+			if !LINTER_MODE {
+				return err
+			}
+			// Report all read errors in one go.
+			if readErr == nil {
+				readErr = err
+			}
+			SkipToTopLevelForm(reader)
+			continue
 		}
 		if phase == READ {
 			continue
 		}
 		expr, err := TryParse(obj, parseContext)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
+			PrintError(GLOBAL_ENV.errWriter(), err)
 			return err
 		}
 		if phase == PARSE {
+			if LINTER_MODE {
+				WarnOnControlFlow(expr)
+			}
 			continue
 		}
 		_, err = TryEval(expr)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
+			PrintError(GLOBAL_ENV.errWriter(), err)
 			return err
 		}
 	}
@@ -1326,12 +1613,12 @@ func intern(name string, proc Proc) {
 	vr.meta = privateMeta
 }
 
-func processData(data []byte) {
-	currentNamespace := GLOBAL_ENV.ns.Value
-	GLOBAL_ENV.ns.Value = GLOBAL_ENV.CoreNamespace
+func processData(data []byte, ns *Namespace) {
+	currentNamespace := GLOBAL_ENV.ns.get()
+	GLOBAL_ENV.ns.set(ns)
 	reader := bytes.NewReader(data)
-	ProcessReader(NewReader(reader, "<joker.core>"), "", EVAL)
-	GLOBAL_ENV.ns.Value = currentNamespace
+	ProcessReader(NewReader(reader, "<"+ns.Name.Name()+">"), "", EVAL)
+	GLOBAL_ENV.ns.set(currentNamespace)
 }
 
 func ProcessLinterData(dialect Dialect) {
@@ -1351,8 +1638,14 @@ func ProcessLinterData(dialect Dialect) {
 
 func init() {
 	rand.Seed(time.Now().UnixNano())
+	initCoreNamespace()
+}
+
+// Fills the core namespace of GLOBAL_ENV with built-in procs
+// and definitions from core.joke.
+func initCoreNamespace() {
 	GLOBAL_ENV.CoreNamespace.InternVar("*assert*", Bool{B: true},
-		MakeMeta(nil, "When set to logical false, assert is a noop. Defaults to true.", "1.0"))
+		MakeMeta(nil, "When set to logical false, assert is a noop. Defaults to true.", "1.0").Assoc(MakeKeyword("dynamic"), Bool{B: true}).(Map))
 
 	intern("list**", procList)
 	intern("cons*", procCons)
@@ -1379,6 +1672,10 @@ func init() {
 	intern("apply*", procApply)
 	intern("lazy-seq*", procLazySeq)
 	intern("delay*", procDelay)
+	intern("future-call*", procFutureCall)
+	intern("promise*", procPromise)
+	intern("deliver*", procDeliver)
+	intern("cpu-count*", procCpuCount)
 	intern("force*", procForce)
 	intern("identical*", procIdentical)
 	intern("compare*", procCompare)
@@ -1467,6 +1764,22 @@ func init() {
 	intern("ns-unalias*", procNamespaceUnalias)
 	intern("var-get*", procVarGet)
 	intern("var-set*", procVarSet)
+	intern("alter-var-root*", procAlterVarRoot)
+	intern("push-thread-bindings*", procPushThreadBindings)
+	intern("pop-thread-bindings*", procPopThreadBindings)
+	intern("get-thread-bindings*", procGetThreadBindings)
+	intern("protocol*", procProtocol)
+	intern("protocol-method*", procProtocolMethod)
+	intern("extend*", procExtend)
+	intern("extends?*", procExtends)
+	intern("extenders*", procExtenders)
+	intern("satisfies?*", procSatisfies)
+	intern("reify*", procReify)
+	intern("deftype*", procDeftype)
+	intern("new-instance*", procNewInstance)
+	intern("map->record*", procMapToRecord)
+	intern("set-object-methods*", procSetObjectMethods)
+	intern("record?*", procIsRecord)
 	intern("ns-resolve*", procNsResolve)
 	intern("array-map*", procArrayMap)
 	intern("buffer*", procBuffer)
@@ -1474,6 +1787,11 @@ func init() {
 	intern("ex-data*", procExData)
 	intern("regex*", procRegex)
 	intern("re-seq*", procReSeq)
+	intern("inst*", procInst)
+	intern("inst-ms*", procInstMs)
+	intern("uuid*", procUUID)
+	intern("parse-uuid*", procParseUUID)
+	intern("random-uuid*", procRandomUUID)
 	intern("re-find*", procReFind)
 	intern("rand*", procRand)
 	intern("special-symbol?*", procIsSpecialSymbol)
@@ -1483,13 +1801,22 @@ func init() {
 	intern("atom*", procAtom)
 	intern("deref*", procDeref)
 	intern("swap*", procSwap)
+	intern("swap-vals*", procSwapVals)
 	intern("reset*", procReset)
+	intern("reset-vals*", procResetVals)
+	intern("compare-and-set*", procCompareAndSet)
+	intern("add-watch*", procAddWatch)
+	intern("remove-watch*", procRemoveWatch)
+	intern("set-validator*", procSetValidator)
+	intern("get-validator*", procGetValidator)
 	intern("alter-meta*", procAlterMeta)
 	intern("reset-meta*", procResetMeta)
 	intern("empty*", procEmpty)
 	intern("bound?*", procIsBound)
+	intern("thread-bound?*", procIsThreadBound)
 	intern("format*", procFormat)
 	intern("load-file*", procLoadFile)
+	intern("load-native*", procLoadNative)
 	intern("reduce-kv*", procReduceKv)
 	intern("slurp*", procSlurp)
 	intern("spit*", procSpit)
@@ -1502,5 +1829,22 @@ func init() {
 	intern("lib-path*", procLibPath)
 	intern("intern-fake-var*", procInternFakeVar)
 
-	processData(coreData)
+	processData(coreData, GLOBAL_ENV.CoreNamespace)
+	initAsyncNamespace()
+}
+
+// joker.async is defined in data/async.joke on top of
+// the procs interned here.
+func initAsyncNamespace() {
+	ns := GLOBAL_ENV.EnsureNamespace(MakeSymbol("joker.async"))
+	ns.ResetMeta(MakeMeta(nil, "Provides channels and go blocks in the style of clojure.core.async, backed by Go channels and goroutines.", "1.0"))
+	ns.ReferAll(GLOBAL_ENV.CoreNamespace)
+	ns.InternVar("chan*", procChan, privateMeta)
+	ns.InternVar("put*", procPut, privateMeta)
+	ns.InternVar("take*", procTake, privateMeta)
+	ns.InternVar("close*", procClose, privateMeta)
+	ns.InternVar("alts*", procAlts, privateMeta)
+	ns.InternVar("timeout*", procTimeout, privateMeta)
+	ns.InternVar("thread-call*", procThreadCall, privateMeta)
+	processData(asyncData, ns)
 }
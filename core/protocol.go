@@ -0,0 +1,197 @@
+package core
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type (
+	// Protocol is a named set of methods that can be implemented
+	// for any type, including built-in ones (see extend).
+	// Methods dispatch on the type of their first argument.
+	Protocol struct {
+		name    Symbol
+		methods []string
+		impls   map[*Type]map[string]Callable
+		// Extended types in the order they were extended.
+		types []*Type
+	}
+	// Object created by reify. It carries its own method
+	// implementations, since they may close over locals.
+	Reified struct {
+		MetaHolder
+		impls map[*Protocol]map[string]Callable
+	}
+)
+
+func MakeProtocol(name Symbol, methods []string) *Protocol {
+	return &Protocol{
+		name:    name,
+		methods: methods,
+		impls:   make(map[*Type]map[string]Callable),
+	}
+}
+
+func (p *Protocol) ToString(escape bool) string {
+	return "#object[Protocol " + p.name.ToString(false) + "]"
+}
+
+func (p *Protocol) Equals(other interface{}) bool {
+	return p == other
+}
+
+func (p *Protocol) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (p *Protocol) GetType() *Type {
+	return TYPES["Protocol"]
+}
+
+func (p *Protocol) Hash() uint32 {
+	return hashPtr(uintptr(unsafe.Pointer(p)))
+}
+
+func (p *Protocol) WithInfo(info *ObjectInfo) Object {
+	return p
+}
+
+func (p *Protocol) hasMethod(name string) bool {
+	for _, m := range p.methods {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Converts a map of method keywords to fns, as passed to extend.
+func (p *Protocol) methodMap(m Map) map[string]Callable {
+	res := make(map[string]Callable)
+	for iter := m.Iter(); iter.HasNext(); {
+		e := iter.Next()
+		name := AssertKeyword(e.key, "Protocol method name must be a keyword, got "+e.key.ToString(true)).ToString(false)[1:]
+		if !p.hasMethod(name) {
+			panic(RT.NewError("No method " + name + " in protocol " + p.name.ToString(false)))
+		}
+		res[name] = AssertCallable(e.value, "Protocol method implementation must be a fn, got "+e.value.ToString(true))
+	}
+	return res
+}
+
+// Sets implementations of protocol methods for type t,
+// replacing previous ones.
+func (p *Protocol) Extend(t *Type, m Map) {
+	if _, ok := p.impls[t]; !ok {
+		p.types = append(p.types, t)
+	}
+	p.impls[t] = p.methodMap(m)
+}
+
+// Returns true if t itself (not its interfaces) extends the protocol.
+func (p *Protocol) Extends(t *Type) bool {
+	_, ok := p.impls[t]
+	return ok
+}
+
+func (p *Protocol) Extenders() Object {
+	res := EmptyVector
+	for _, t := range p.types {
+		res = res.Conjoin(t)
+	}
+	return res.Seq()
+}
+
+// Finds implementations of the protocol for obj. Exact type is
+// checked first, then interfaces the protocol was extended to
+// (e.g. Seqable or Map) and finally Object, which covers everything
+// except nil. Of the interfaces obj implements, the most specific one
+// wins (Map rather than Seqable, since every Map is Seqable); if none
+// of them is more specific than the others, the one extended first does.
+func (p *Protocol) implsFor(obj Object) map[string]Callable {
+	if r, ok := obj.(*Reified); ok {
+		if res, ok := r.impls[p]; ok {
+			return res
+		}
+	}
+	if res, ok := p.impls[obj.GetType()]; ok {
+		return res
+	}
+	object := TYPES["Object"]
+	var best *Type
+	for _, t := range p.types {
+		if t != object && t.reflectType.Kind() == reflect.Interface && IsInstance(t, obj) {
+			if best == nil || (t.reflectType != best.reflectType && t.reflectType.Implements(best.reflectType)) {
+				best = t
+			}
+		}
+	}
+	if best != nil {
+		return p.impls[best]
+	}
+	if !obj.Equals(NIL) {
+		return p.impls[object]
+	}
+	return nil
+}
+
+func (p *Protocol) Satisfies(obj Object) bool {
+	return p.implsFor(obj) != nil
+}
+
+// Returns the proc that dispatches method name
+// on the type of its first argument.
+func (p *Protocol) Method(name string) Proc {
+	return func(args []Object) Object {
+		if len(args) == 0 {
+			panicArity(0)
+		}
+		fn, ok := p.implsFor(args[0])[name]
+		if !ok {
+			panic(RT.NewError("No implementation of method: " + name + " of protocol: " + p.name.ToString(false) +
+				" found for type: " + args[0].GetType().ToString(false)))
+		}
+		return fn.Call(args)
+	}
+}
+
+// Creates a reified object. impls alternates protocols
+// and maps of their method implementations.
+func MakeReified(impls []Object) *Reified {
+	res := &Reified{impls: make(map[*Protocol]map[string]Callable)}
+	for i := 0; i+1 < len(impls); i += 2 {
+		p := AssertProtocol(impls[i], "reify expects a protocol, got "+impls[i].ToString(true))
+		res.impls[p] = p.methodMap(AssertMap(impls[i+1], ""))
+	}
+	return res
+}
+
+func (r *Reified) ToString(escape bool) string {
+	return "#object[Reified]"
+}
+
+func (r *Reified) Equals(other interface{}) bool {
+	return r == other
+}
+
+func (r *Reified) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (r *Reified) GetType() *Type {
+	return TYPES["Reified"]
+}
+
+func (r *Reified) Hash() uint32 {
+	return hashPtr(uintptr(unsafe.Pointer(r)))
+}
+
+func (r *Reified) WithInfo(info *ObjectInfo) Object {
+	return r
+}
+
+func (r *Reified) WithMeta(meta Map) Object {
+	res := *r
+	res.meta = SafeMerge(res.meta, meta)
+	return &res
+}
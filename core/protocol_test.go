@@ -0,0 +1,44 @@
+package core
+
+import "testing"
+
+func TestProtocolInterfaceOrder(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `
+(defprotocol Q (q [x]))
+(extend-protocol Q
+  Seqable (q [_] :seqable)
+  Map (q [_] :map)
+  Object (q [_] :object))
+(defprotocol R (r [x]))
+(extend-protocol R
+  Map (r [_] :map)
+  Seqable (r [_] :seqable))
+(defprotocol S (s [x]))
+(extend-protocol S
+  Counted (s [_] :counted)
+  Sequential (s [_] :sequential))
+(defprotocol T (t [x]))
+(extend-protocol T
+  Sequential (t [_] :sequential)
+  Counted (t [_] :counted)
+  Vector (t [_] :vector))`)
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(q {})`, `:map`},
+		{`(q [1])`, `:seqable`},
+		{`(q 1)`, `:object`},
+		{`(r {})`, `:map`},
+		{`(r '(1))`, `:seqable`},
+		{`(s [1])`, `:counted`},
+		{`(t [1])`, `:vector`},
+		{`(t '(1))`, `:sequential`},
+	}
+	for _, test := range tests {
+		if res := evalString(t, in, test.src).ToString(true); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expected, res)
+		}
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -23,6 +25,10 @@ type (
 		line   int
 		column int
 	}
+	// Forms produced by a reader conditional that are to be spliced
+	// into the enclosing collection. Reader conditional that doesn't
+	// match any feature produces no forms. Never escapes the reader.
+	readerSplice []Object
 )
 
 const EOF = -1
@@ -397,7 +403,7 @@ func readSymbol(reader *Reader, first rune) Object {
 			sym := MakeSymbol(str[1:])
 			ns := GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(), sym)
 			if ns == nil {
-				msg := fmt.Sprintf("Unable to resolve namespace %s in keyword %s", *sym.ns, ":"+str)
+				msg := fmt.Sprintf("Unable to resolve namespace %s in keyword %s", *sym.ns, ":"+str) + namespaceSuggestions(*sym.ns)
 				if LINTER_MODE {
 					printReadWarning(reader, msg)
 					return MakeReadObject(reader, MakeKeyword(*sym.name))
@@ -480,17 +486,91 @@ func readString(reader *Reader, isRegex bool) Object {
 	return MakeReadObject(reader, String{S: b.String()})
 }
 
-func readList(reader *Reader) Object {
-	s := make([]Object, 0, 10)
+func (rs readerSplice) ToString(escape bool) string {
+	return "#object[readerSplice]"
+}
+
+func (rs readerSplice) Equals(other interface{}) bool {
+	return false
+}
+
+func (rs readerSplice) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (rs readerSplice) WithInfo(info *ObjectInfo) Object {
+	return rs
+}
+
+func (rs readerSplice) GetType() *Type {
+	return nil
+}
+
+func (rs readerSplice) Hash() uint32 {
+	return 0
+}
+
+// In linter mode, a form that starts in the first column inside a collection
+// is likely to be the next top level form, meaning that the collection
+// is missing its closing delimiter. This is only a guess (forms inside
+// comment, for example, often start in the first column), so the reader
+// just remembers it and it's reported only if reading fails.
+func markUnclosed(reader *Reader, closing rune) {
+	if !LINTER_MODE || reader.unclosed != nil || reader.column != 0 || reader.Peek() != '(' || len(posStack) == 0 {
+		return
+	}
+	p := posStack[len(posStack)-1]
+	reader.unclosed = ReadError{
+		line:     p.line,
+		column:   p.column,
+		filename: reader.filename,
+		msg:      fmt.Sprintf("Unclosed delimiter, expected %c before the next top level form at line %d", closing, reader.line),
+	}
+}
+
+// Skips input up to the next line that starts with an opening paren,
+// which is likely to be the beginning of the next top level form.
+// Used by linter to continue reading after read errors.
+func SkipToTopLevelForm(reader *Reader) {
+	ARGS = nil
+	posStack = posStack[:0]
+	for {
+		if reader.column == 0 && reader.Peek() == '(' {
+			return
+		}
+		if reader.Get() == EOF {
+			return
+		}
+	}
+}
+
+// Reads forms until closing character, splicing the results
+// of reader conditionals, and calls f for each of them.
+func readElements(reader *Reader, closing rune, f func(obj Object)) {
 	eatWhitespace(reader)
+	markUnclosed(reader, closing)
 	r := reader.Peek()
-	for r != ')' {
-		obj := Read(reader)
-		s = append(s, obj)
+	for r != closing {
+		switch obj := readForm(reader).(type) {
+		case readerSplice:
+			for _, o := range obj {
+				f(o)
+			}
+		default:
+			f(obj)
+		}
 		eatWhitespace(reader)
+		markUnclosed(reader, closing)
 		r = reader.Peek()
 	}
 	reader.Get()
+}
+
+func readList(reader *Reader) Object {
+	s := make([]Object, 0, 10)
+	readElements(reader, ')', func(obj Object) {
+		s = append(s, obj)
+	})
 	list := EmptyList
 	for i := len(s) - 1; i >= 0; i-- {
 		list = list.conj(s[i])
@@ -501,69 +581,125 @@ func readList(reader *Reader) Object {
 
 func readVector(reader *Reader) Object {
 	result := EmptyVector
-	eatWhitespace(reader)
-	r := reader.Peek()
-	for r != ']' {
-		obj := Read(reader)
+	readElements(reader, ']', func(obj Object) {
 		result = result.Conjoin(obj)
-		eatWhitespace(reader)
-		r = reader.Peek()
-	}
-	reader.Get()
+	})
 	return MakeReadObject(reader, result)
 }
 
-func readHashMap(reader *Reader, hashMap *HashMap) Object {
-	eatWhitespace(reader)
-	r := reader.Peek()
-	for r != '}' {
-		key := Read(reader)
-		value := Read(reader)
-		if hashMap.containsKey(key) {
-			panic(MakeReadError(reader, "Duplicate key "+key.ToString(false)))
+func readMap(reader *Reader) Object {
+	return MakeReadObject(reader, readMapElements(reader, nil))
+}
+
+// Reads map elements up to the closing brace. If qualify is not nil,
+// it's applied to every key before the key is added to the map.
+func readMapElements(reader *Reader, qualify func(Object) Object) Map {
+	var m Map = EmptyArrayMap()
+	var key Object
+	readElements(reader, '}', func(obj Object) {
+		if key == nil {
+			key = obj
+			if qualify != nil {
+				key = qualify(key)
+			}
+			return
 		}
-		hashMap = hashMap.Assoc(key, value).(*HashMap)
-		eatWhitespace(reader)
-		r = reader.Peek()
+		switch mp := m.(type) {
+		case *ArrayMap:
+			if !mp.Add(key, obj) {
+				panic(MakeReadError(reader, "Duplicate key "+key.ToString(false)))
+			}
+			if len(mp.arr) > HASHMAP_THRESHOLD {
+				m = NewHashMap(mp.arr...)
+			}
+		case *HashMap:
+			if mp.containsKey(key) {
+				panic(MakeReadError(reader, "Duplicate key "+key.ToString(false)))
+			}
+			m = mp.Assoc(key, obj).(*HashMap)
+		}
+		key = nil
+	})
+	if key != nil {
+		panic(MakeReadError(reader, "Map literal must contain an even number of forms"))
 	}
-	reader.Get()
-	return MakeReadObject(reader, hashMap)
+	return m
 }
 
-func readMap(reader *Reader) Object {
-	m := EmptyArrayMap()
-	eatWhitespace(reader)
-	r := reader.Peek()
-	for r != '}' {
-		key := Read(reader)
-		value := Read(reader)
-		if !m.Add(key, value) {
-			panic(MakeReadError(reader, "Duplicate key "+key.ToString(false)))
+// Qualifies unqualified keyword and symbol keys with ns.
+// Keys with _ namespace are stripped of it.
+func qualifyMapKey(key Object, ns string) Object {
+	switch k := key.(type) {
+	case Keyword:
+		if k.ns == nil {
+			return DeriveReadObject(key, MakeKeyword(ns+"/"+*k.name))
 		}
-		if len(m.arr) > HASHMAP_THRESHOLD {
-			hashMap := NewHashMap(m.arr...)
-			return readHashMap(reader, hashMap)
+		if *k.ns == "_" {
+			return DeriveReadObject(key, MakeKeyword(*k.name))
+		}
+	case Symbol:
+		if k.ns == nil {
+			return DeriveReadObject(key, MakeSymbol(ns+"/"+*k.name))
+		}
+		if *k.ns == "_" {
+			return DeriveReadObject(key, MakeSymbol(*k.name))
 		}
-		eatWhitespace(reader)
-		r = reader.Peek()
 	}
-	reader.Get()
-	return MakeReadObject(reader, m)
+	return key
+}
+
+// Reads namespaced map literal (#:ns{...}, #::{...} or #::alias{...}).
+// The leading #: has already been consumed.
+func readNamespacedMap(reader *Reader) Object {
+	isAuto := false
+	if reader.Peek() == ':' {
+		reader.Get()
+		isAuto = true
+	}
+	var b bytes.Buffer
+	r := reader.Get()
+	for r != '{' && isSymbolRune(r) {
+		b.WriteRune(r)
+		r = reader.Get()
+	}
+	reader.Unget()
+	eatWhitespace(reader)
+	if reader.Get() != '{' {
+		panic(MakeReadError(reader, "Namespaced map must specify a map"))
+	}
+	ns := b.String()
+	switch {
+	case isAuto && ns == "":
+		ns = GLOBAL_ENV.CurrentNamespace().Name.Name()
+	case isAuto:
+		n := GLOBAL_ENV.NamespaceFor(GLOBAL_ENV.CurrentNamespace(), MakeSymbol(ns+"/_"))
+		if n == nil {
+			msg := "Unable to resolve namespace " + ns + " in namespaced map" + namespaceSuggestions(ns)
+			if !LINTER_MODE {
+				panic(MakeReadError(reader, msg))
+			}
+			printReadWarning(reader, msg)
+		} else {
+			n.isUsed = true
+			ns = n.Name.Name()
+		}
+	case ns == "":
+		panic(MakeReadError(reader, "Namespaced map must specify a namespace"))
+	case strings.ContainsRune(ns, '/'):
+		panic(MakeReadError(reader, "Namespaced map namespace must not be qualified: "+ns))
+	}
+	return MakeReadObject(reader, readMapElements(reader, func(key Object) Object {
+		return qualifyMapKey(key, ns)
+	}))
 }
 
 func readSet(reader *Reader) Object {
 	set := EmptySet()
-	eatWhitespace(reader)
-	r := reader.Peek()
-	for r != '}' {
-		obj := Read(reader)
+	readElements(reader, '}', func(obj Object) {
 		if !set.Add(obj) {
 			panic(MakeReadError(reader, "Duplicate set element "+obj.ToString(false)))
 		}
-		eatWhitespace(reader)
-		r = reader.Peek()
-	}
-	reader.Get()
+	})
 	return MakeReadObject(reader, set)
 }
 
@@ -766,33 +902,86 @@ func handleNoReaderError(reader *Reader, s Symbol) Object {
 	panic(MakeReadError(reader, "No reader function for tag "+s.ToString(false)))
 }
 
+// Returns reader function for tag from readers map, or nil if
+// the map doesn't have one. Reader functions declared in data readers
+// files are symbols that are resolved at the time the tag is read.
+func lookupDataReader(reader *Reader, readers Object, tag Symbol) Callable {
+	m, ok := readers.(Map)
+	if !ok {
+		return nil
+	}
+	ok, readFunc := m.Get(tag)
+	if !ok {
+		return nil
+	}
+	switch f := readFunc.(type) {
+	case Symbol:
+		vr, ok := GLOBAL_ENV.Resolve(f)
+		if !ok {
+			panic(MakeReadError(reader, "Unable to resolve reader function "+f.ToString(false)+" for tag "+tag.ToString(false)))
+		}
+		return vr
+	case Callable:
+		return f
+	default:
+		panic(MakeReadError(reader, "Reader function for tag "+tag.ToString(false)+" is not a function: "+f.ToString(true)))
+	}
+}
+
+// Reads instances of types defined with deftype or defrecord,
+// e.g. #user.Point[1 2] or (records only) #user.Point{:x 1 :y 2}.
+// Field values are not evaluated.
+func readTypeLiteral(reader *Reader, t *Type) Object {
+	switch v := Read(reader).(type) {
+	case *Vector:
+		if v.Count() == len(t.fields) {
+			return t.NewInstance(ToSlice(v.Seq()))
+		}
+	case Map:
+		if t.IsRecord() {
+			return t.NewRecord(v)
+		}
+	}
+	panic(MakeReadError(reader, "Unreadable constructor form for type "+t.ToString(false)))
+}
+
 func readTagged(reader *Reader) Object {
 	obj := Read(reader)
 	switch s := obj.(type) {
 	case Symbol:
-		readersVar, ok := GLOBAL_ENV.CoreNamespace.mappings[MakeSymbol("default-data-readers").name]
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if t := GLOBAL_ENV.FindType(s.ToString(false)); t != nil && t.fields != nil && !LINTER_MODE {
+			return readTypeLiteral(reader, t)
 		}
-		readersMap, ok := readersVar.Value.(Map)
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if LINTER_MODE {
+			// User defined reader functions are not available to the linter,
+			// so the tag being declared is all it needs to know.
+			if m, ok := GLOBAL_ENV.dataReaders.Resolve().(Map); ok {
+				if ok, _ := m.Get(s); ok {
+					return Read(reader)
+				}
+			}
+		} else if readFunc := lookupDataReader(reader, GLOBAL_ENV.dataReaders.Resolve(), s); readFunc != nil {
+			return readFunc.Call([]Object{Read(reader)})
 		}
-		ok, readFunc := readersMap.Get(s)
-		if !ok {
-			return handleNoReaderError(reader, s)
+		if readersVar, ok := GLOBAL_ENV.CoreNamespace.mappings[MakeSymbol("default-data-readers").name]; ok {
+			if readFunc := lookupDataReader(reader, readersVar.Value, s); readFunc != nil {
+				return readFunc.Call([]Object{Read(reader)})
+			}
 		}
-		return AssertVar(readFunc, "").Call([]Object{Read(reader)})
+		if readFunc, ok := GLOBAL_ENV.defaultDataReaderFn.Resolve().(Callable); ok && !LINTER_MODE {
+			return readFunc.Call([]Object{s, Read(reader)})
+		}
+		return handleNoReaderError(reader, s)
 	default:
 		panic(MakeReadError(reader, "Reader tag must be a symbol"))
 	}
 }
 
 func readConditional(reader *Reader) Object {
+	isSplicing := false
 	if reader.Peek() == '@' {
-		// Ignoring splicing for now
-		// TODO: implement support for splicing
 		reader.Get()
+		isSplicing = true
 	}
 	eatWhitespace(reader)
 	r := reader.Get()
@@ -809,11 +998,24 @@ func readConditional(reader *Reader) Object {
 	}
 	for cond.count > 0 {
 		if ok, _ := GLOBAL_ENV.Features.Get(cond.first); ok {
-			return Second(cond)
+			if !isSplicing {
+				return Second(cond)
+			}
+			switch forms := Second(cond).(type) {
+			case *List:
+				return readerSplice(ToSlice(forms))
+			case *Vector:
+				return readerSplice(ToSlice(forms.Seq()))
+			}
+			if LINTER_MODE {
+				printReadWarning(reader, "Spliced form in reader conditional must be a list or vector")
+				return readerSplice{}
+			}
+			panic(MakeReadError(reader, "Spliced form in reader conditional must be a list or vector"))
 		}
 		cond = cond.rest.rest
 	}
-	return Read(reader)
+	return readerSplice{}
 }
 
 func readDispatch(reader *Reader) Object {
@@ -840,12 +1042,34 @@ func readDispatch(reader *Reader) Object {
 		return res
 	case '?':
 		return readConditional(reader)
+	case ':':
+		return readNamespacedMap(reader)
+	case '#':
+		return readSymbolicValue(reader)
 	}
 	popPos()
 	reader.Unget()
 	return readTagged(reader)
 }
 
+// Reads ##Inf, ##-Inf and ##NaN. The leading ## has already been consumed.
+func readSymbolicValue(reader *Reader) Object {
+	var b bytes.Buffer
+	for r := reader.Get(); isSymbolRune(r); r = reader.Get() {
+		b.WriteRune(r)
+	}
+	reader.Unget()
+	switch b.String() {
+	case "Inf":
+		return MakeReadObject(reader, Double{D: math.Inf(1)})
+	case "-Inf":
+		return MakeReadObject(reader, Double{D: math.Inf(-1)})
+	case "NaN":
+		return MakeReadObject(reader, Double{D: math.NaN()})
+	}
+	panic(MakeReadError(reader, "Unknown symbolic value: ##"+b.String()))
+}
+
 func readWithMeta(reader *Reader) Object {
 	meta := readMeta(reader)
 	nextObj := Read(reader)
@@ -857,7 +1081,26 @@ func readWithMeta(reader *Reader) Object {
 	}
 }
 
+// Reads one form. Reader conditionals that don't match any feature
+// are skipped. Splicing is only allowed inside collections.
 func Read(reader *Reader) Object {
+	for {
+		obj := readForm(reader)
+		forms, ok := obj.(readerSplice)
+		if !ok {
+			return obj
+		}
+		if len(forms) > 0 {
+			if !LINTER_MODE {
+				panic(MakeReadError(reader, "Reader conditional splicing not allowed at the top level"))
+			}
+			printReadWarning(reader, "Reader conditional splicing not allowed at the top level")
+			return DeriveReadObject(forms[0], NewListFrom(forms...).Cons(MakeSymbol("do")))
+		}
+	}
+}
+
+func readForm(reader *Reader) Object {
 	eatWhitespace(reader)
 	r := reader.Get()
 	pushPos(reader)
@@ -932,6 +1175,7 @@ func TryRead(reader *Reader) (obj Object, err error) {
 			}
 		}
 	}()
+	reader.unclosed = nil
 	eatWhitespace(reader)
 	if reader.Peek() == EOF {
 		return NIL, io.EOF
@@ -14,6 +14,9 @@ type (
 		isEof          bool
 		rewind         int
 		filename       *string
+		// Likely cause of the read error, if the form
+		// being read turns out to be broken (see markUnclosed).
+		unclosed error
 	}
 )
 
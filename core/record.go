@@ -0,0 +1,280 @@
+package core
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+type (
+	// Instance of a type defined with defrecord. Records are maps
+	// of their fields (in the order they were declared) and any other
+	// keys assoc'ed to them.
+	Record struct {
+		MetaHolder
+		rtype *Type
+		m     Map
+	}
+	// Instance of a type defined with deftype. Since there is no
+	// interop, fields are accessed with keywords, e.g. (:x obj).
+	TypeInstance struct {
+		MetaHolder
+		rtype  *Type
+		fields []Object
+	}
+)
+
+// Defines a new type named name (fully qualified, e.g. user.Point)
+// in the current environment, replacing the previous definition if any.
+func DefineType(name string, fields []Keyword, isRecord bool) *Type {
+	var inst interface{} = (*TypeInstance)(nil)
+	if isRecord {
+		inst = (*Record)(nil)
+	}
+	res := &Type{
+		name:        name,
+		reflectType: reflect.TypeOf(inst),
+		fields:      append(make([]Keyword, 0, len(fields)), fields...),
+	}
+	GLOBAL_ENV.types[name] = res
+	return res
+}
+
+func (t *Type) IsRecord() bool {
+	return t.fields != nil && t.reflectType == reflect.TypeOf((*Record)(nil))
+}
+
+// Creates an instance of type t (which must be defined
+// with deftype or defrecord) from values of its fields.
+func (t *Type) NewInstance(values []Object) Object {
+	if t.fields == nil {
+		panic(RT.NewError("Can't create instances of built-in type " + t.name))
+	}
+	if len(values) != len(t.fields) {
+		panic(RT.NewError("Wrong number of fields passed to constructor of " + t.name))
+	}
+	if !t.IsRecord() {
+		return &TypeInstance{rtype: t, fields: append([]Object{}, values...)}
+	}
+	m := EmptyArrayMap()
+	for i, f := range t.fields {
+		m.Add(f, values[i])
+	}
+	return &Record{rtype: t, m: m}
+}
+
+// Creates a record of type t from map m. Missing fields are nil.
+func (t *Type) NewRecord(m Map) *Record {
+	if !t.IsRecord() {
+		panic(RT.NewError(t.name + " is not a record type"))
+	}
+	fields := EmptyArrayMap()
+	for _, f := range t.fields {
+		fields.Add(f, NIL)
+	}
+	var res Map = fields
+	for iter := m.Iter(); iter.HasNext(); {
+		p := iter.Next()
+		res = res.Assoc(p.key, p.value).(Map)
+	}
+	return &Record{rtype: t, m: res}
+}
+
+// Returns the implementation of Object method name
+// (e.g. toString) or nil if the type doesn't define it.
+func (t *Type) objectMethod(name string) Callable {
+	if t.objectMethods == nil {
+		return nil
+	}
+	if ok, fn := t.objectMethods.Get(MakeKeyword(name)); ok {
+		return AssertCallable(fn, "Object method "+name+" must be a function")
+	}
+	return nil
+}
+
+// toString is only used by str (escape is false),
+// printed representation can't be changed.
+func (t *Type) toString(obj Object, escape bool) (string, bool) {
+	if fn := t.objectMethod("toString"); fn != nil && !escape {
+		return AssertString(fn.Call([]Object{obj}), "toString must return a string").S, true
+	}
+	return "", false
+}
+
+func (t *Type) equals(obj Object, other interface{}) (bool, bool) {
+	if fn := t.objectMethod("equals"); fn != nil {
+		o, ok := other.(Object)
+		if !ok {
+			return false, true
+		}
+		return toBool(fn.Call([]Object{obj, o})), true
+	}
+	return false, false
+}
+
+func (t *Type) hash(obj Object) (uint32, bool) {
+	if fn := t.objectMethod("hashCode"); fn != nil {
+		return uint32(AssertInt(fn.Call([]Object{obj}), "hashCode must return an integer").I), true
+	}
+	return 0, false
+}
+
+func (t *Type) isField(key Object) bool {
+	for _, f := range t.fields {
+		if f.Equals(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Record) ToString(escape bool) string {
+	if s, ok := r.rtype.toString(r, escape); ok {
+		return s
+	}
+	return "#" + r.rtype.name + mapToString(r.m, escape)
+}
+
+func (r *Record) Equals(other interface{}) bool {
+	if r == other {
+		return true
+	}
+	if res, ok := r.rtype.equals(r, other); ok {
+		return res
+	}
+	o, ok := other.(*Record)
+	return ok && r.rtype == o.rtype && mapEquals(r.m, o.m)
+}
+
+func (r *Record) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (r *Record) GetType() *Type {
+	return r.rtype
+}
+
+func (r *Record) Hash() uint32 {
+	if h, ok := r.rtype.hash(r); ok {
+		return h
+	}
+	return r.m.Hash() ^ String{S: r.rtype.name}.Hash()
+}
+
+func (r *Record) WithInfo(info *ObjectInfo) Object {
+	return r
+}
+
+func (r *Record) WithMeta(meta Map) Object {
+	res := *r
+	res.meta = SafeMerge(res.meta, meta)
+	return &res
+}
+
+func (r *Record) with(m Map) *Record {
+	res := *r
+	res.m = m
+	return &res
+}
+
+// Unlike maps, records can't be empty, since fields
+// are always there.
+func (r *Record) Empty() Collection {
+	panic(RT.NewError("Can't create empty: " + r.rtype.name))
+}
+
+func (r *Record) Count() int {
+	return r.m.Count()
+}
+
+func (r *Record) Seq() Seq {
+	return r.m.Seq()
+}
+
+func (r *Record) Get(key Object) (bool, Object) {
+	return r.m.Get(key)
+}
+
+func (r *Record) EntryAt(key Object) *Vector {
+	return r.m.EntryAt(key)
+}
+
+func (r *Record) Assoc(key Object, value Object) Associative {
+	return r.with(r.m.Assoc(key, value).(Map))
+}
+
+func (r *Record) Conj(obj Object) Conjable {
+	return mapConj(r, obj)
+}
+
+// Removing a field turns the record into a plain map.
+func (r *Record) Without(key Object) Map {
+	if r.rtype.isField(key) {
+		return r.m.Without(key)
+	}
+	return r.with(r.m.Without(key))
+}
+
+func (r *Record) Keys() Seq {
+	return r.m.Keys()
+}
+
+func (r *Record) Vals() Seq {
+	return r.m.Vals()
+}
+
+func (r *Record) Merge(other Map) Map {
+	return r.with(r.m.Merge(other))
+}
+
+func (r *Record) Iter() MapIterator {
+	return r.m.Iter()
+}
+
+func (obj *TypeInstance) ToString(escape bool) string {
+	if s, ok := obj.rtype.toString(obj, escape); ok {
+		return s
+	}
+	return "#object[" + obj.rtype.name + "]"
+}
+
+func (obj *TypeInstance) Equals(other interface{}) bool {
+	if obj == other {
+		return true
+	}
+	res, _ := obj.rtype.equals(obj, other)
+	return res
+}
+
+func (obj *TypeInstance) GetInfo() *ObjectInfo {
+	return nil
+}
+
+func (obj *TypeInstance) GetType() *Type {
+	return obj.rtype
+}
+
+func (obj *TypeInstance) Hash() uint32 {
+	if h, ok := obj.rtype.hash(obj); ok {
+		return h
+	}
+	return hashPtr(uintptr(unsafe.Pointer(obj)))
+}
+
+func (obj *TypeInstance) WithInfo(info *ObjectInfo) Object {
+	return obj
+}
+
+func (obj *TypeInstance) WithMeta(meta Map) Object {
+	res := *obj
+	res.meta = SafeMerge(res.meta, meta)
+	return &res
+}
+
+func (obj *TypeInstance) Get(key Object) (bool, Object) {
+	for i, f := range obj.rtype.fields {
+		if f.Equals(key) {
+			return true, obj.fields[i]
+		}
+	}
+	return false, nil
+}
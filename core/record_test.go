@@ -0,0 +1,51 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordObjectMethods(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `
+(defrecord S [a]
+  Object
+  (toString [_] (str "S of " a))
+  (hashCode [_] 42))
+(deftype P [x y]
+  Object
+  (equals [_ o] (and (instance? P o) (= x (:x o)))))`)
+	t.Run("toString", func(t *testing.T) {
+		assertEval(t, in, `(str (->S 1))`, `"S of 1"`)
+		// Printing readably ignores toString.
+		assertEval(t, in, `(pr-str (->S 1))`, `"#user.S{:a 1}"`)
+	})
+	t.Run("hashCode", func(t *testing.T) {
+		assertEval(t, in, `(hash (->S 1))`, `42`)
+		assertEval(t, in, `(= (hash (->S 1)) (hash (->S 2)))`, `true`)
+	})
+	t.Run("equals", func(t *testing.T) {
+		assertEval(t, in, `(= (->P 1 2) (->P 1 3))`, `true`)
+		assertEval(t, in, `(= (->P 1 2) (->P 2 2))`, `false`)
+		// Records without equals compare by fields.
+		assertEval(t, in, `(= (->S 1) (map->S {:a 1}))`, `true`)
+	})
+}
+
+func TestRecordErrors(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `(defrecord R [a])`)
+	tests := []struct {
+		src string
+		msg string
+	}{
+		{`(empty (->R 1))`, "Can't create empty: user.R"},
+		{`(defrecord Q [a] Object (finalize [_] nil))`, "Can't define Object method finalize of Q"},
+	}
+	for _, test := range tests {
+		_, err := in.EvalString(test.src)
+		if err == nil || !strings.Contains(err.Error(), test.msg) {
+			t.Errorf("%s: expected error %q, got %v", test.src, test.msg, err)
+		}
+	}
+}
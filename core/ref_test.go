@@ -0,0 +1,75 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAtoms(t *testing.T) {
+	in := NewInterpreter()
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		// Pointer values must be identical, not just equal.
+		{`(let [v [1] a (atom v)] [(compare-and-set! a [1] 2) @a])`, `[false [1]]`},
+		{`(let [v [1] a (atom v)] [(compare-and-set! a v 2) @a])`, `[true 2]`},
+		{`(let [a (atom 1)] [(compare-and-set! a 1 2) (compare-and-set! a 1 3) @a])`, `[true false 2]`},
+		{`(let [a (atom 1)] [(swap-vals! a + 2) @a])`, `[[1 3] 3]`},
+		{`(let [a (atom 1)] [(reset-vals! a 5) @a])`, `[[1 5] 5]`},
+		{`(let [log (atom [])
+		        a (atom 1)]
+		    (add-watch a :w (fn [k r old new] (swap! log conj [k (identical? r a) old new])))
+		    (reset! a 2)
+		    (swap! a inc)
+		    (compare-and-set! a 3 4)
+		    (remove-watch a :w)
+		    (reset! a 5)
+		    @log)`, `[[:w true 1 2] [:w true 2 3] [:w true 3 4]]`},
+		{`(let [a (atom 1 :validator pos?)]
+		    (try (reset! a -1) (catch Error e nil))
+		    [@a (= pos? (get-validator a))])`, `[1 true]`},
+		{`(let [a (atom 1)]
+		    (try (set-validator! a neg?) (catch Error e nil))
+		    [(get-validator a) (do (set-validator! a pos?) (try (swap! a -) (catch Error e @a)))])`, `[nil 1]`},
+	}
+	for _, test := range tests {
+		if res := evalString(t, in, test.src).ToString(true); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expected, res)
+		}
+	}
+	errs := []string{
+		`(atom -1 :validator pos?)`,
+		`(reset! (atom 1 :validator pos?) -1)`,
+		`(set-validator! (atom -1) pos?)`,
+	}
+	for _, src := range errs {
+		if _, err := in.EvalString(src); err == nil || !strings.Contains(err.Error(), "Invalid reference state") {
+			t.Errorf("%s: expected invalid reference state error, got %v", src, err)
+		}
+	}
+}
+
+func TestVarWatches(t *testing.T) {
+	in := NewInterpreter()
+	evalString(t, in, `
+(def log (atom []))
+(def ^:dynamic *x* 1)
+(add-watch #'*x* :w (fn [k r old new] (swap! log conj [k old new])))`)
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`(do (alter-var-root #'*x* + 1) @log)`, `[[:w 1 2]]`},
+		{`(do (def ^:dynamic *x* 3) @log)`, `[[:w 1 2] [:w 2 3]]`},
+		// Thread-local bindings are not watched.
+		{`(do (binding [*x* 10] (var-set #'*x* 11)) @log)`, `[[:w 1 2] [:w 2 3]]`},
+		{`(do (remove-watch #'*x* :w) (alter-var-root #'*x* inc) [*x* (count @log)])`, `[4 2]`},
+		{`(do (set-validator! #'*x* pos?) (try (alter-var-root #'*x* -) (catch Error e *x*)))`, `4`},
+	}
+	for _, test := range tests {
+		if res := evalString(t, in, test.src).ToString(true); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expected, res)
+		}
+	}
+}
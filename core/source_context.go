@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	colorRed   = "\x1b[31;1m"
+	colorBlue  = "\x1b[34;1m"
+	colorReset = "\x1b[0m"
+)
+
+func errorPosition(err error) (Position, bool) {
+	switch err := err.(type) {
+	case ReadError:
+		return Position{
+			startLine:   err.line,
+			startColumn: err.column,
+			endLine:     err.line,
+			endColumn:   err.column,
+			filename:    err.filename,
+		}, true
+	case *ParseError:
+		return errorPosition(*err)
+	case ParseError:
+		if info := err.obj.GetInfo(); info != nil {
+			return info.Position, true
+		}
+	case *EvalError:
+		// Errors in core functions are more helpful when shown
+		// at the place in user's code they were called from.
+		if !isSourceAvailable(err.pos) && err.rt != nil {
+			frames := err.rt.callstack.frames
+			for i := len(frames) - 1; i >= 0; i-- {
+				if pos := frames[i].traceable.Pos(); isSourceAvailable(pos) {
+					return pos, true
+				}
+			}
+		}
+		return err.pos, true
+	}
+	return Position{}, false
+}
+
+func isSourceAvailable(pos Position) bool {
+	return pos.filename != nil && pos.startLine > 0 && !strings.HasPrefix(*pos.filename, "<")
+}
+
+func sourceLine(filename string, line int) (string, bool) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for i := 1; scanner.Scan(); i++ {
+		if i == line {
+			return strings.TrimRight(scanner.Text(), "\r"), true
+		}
+	}
+	return "", false
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Returns the source line pos points to with the span of pos
+// underlined by carets (up to the end of the line if pos spans
+// several lines), or empty string if the source is not available.
+func sourceContext(pos Position, color bool) string {
+	if !isSourceAvailable(pos) {
+		return ""
+	}
+	line, ok := sourceLine(*pos.filename, pos.startLine)
+	if !ok {
+		return ""
+	}
+	runes := []rune(line)
+	start := pos.startColumn
+	if start < 1 {
+		start = 1
+	}
+	if start > len(runes)+1 {
+		return ""
+	}
+	end := pos.endColumn
+	if pos.endLine != pos.startLine || end > len(runes) {
+		end = len(runes)
+	}
+	if end < start {
+		end = start
+	}
+	// Keep tabs so that carets line up with the source.
+	var prefix bytes.Buffer
+	for _, r := range runes[:start-1] {
+		if r == '\t' {
+			prefix.WriteRune('\t')
+		} else {
+			prefix.WriteRune(' ')
+		}
+	}
+	carets := strings.Repeat("^", end-start+1)
+	lineNumber := strconv.Itoa(pos.startLine)
+	gutter := strings.Repeat(" ", len(lineNumber)) + " |"
+	numbered := lineNumber + " |"
+	if color {
+		gutter = colorBlue + gutter + colorReset
+		numbered = colorBlue + numbered + colorReset
+		carets = colorRed + carets + colorReset
+	}
+	return fmt.Sprintf("%s\n%s %s\n%s %s%s\n", gutter, numbered, line, gutter, prefix.String(), carets)
+}
+
+func sourceLocation(pos Position) string {
+	return fmt.Sprintf("  --> %s:%d:%d\n", pos.Filename(), pos.startLine, pos.startColumn)
+}
+
+// Prints err to w. Unless in linter mode (where the output has
+// to be easy to parse), the first line of the error message is followed
+// by the source code the error points to.
+func PrintError(w io.Writer, err error) {
+	msg := err.Error()
+	pos, ok := errorPosition(err)
+	if LINTER_MODE || !ok {
+		fmt.Fprintln(w, msg)
+		return
+	}
+	color := false
+	if f, ok := w.(*os.File); ok {
+		color = isTerminal(f)
+	}
+	context := sourceContext(pos, color)
+	if e, ok := err.(*EvalError); ok && context != "" && pos != e.pos {
+		context = sourceLocation(pos) + context
+	}
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		fmt.Fprint(w, msg[:i+1], context, msg[i+1:], "\n")
+		return
+	}
+	fmt.Fprint(w, msg, "\n", context)
+}
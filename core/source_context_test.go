@@ -0,0 +1,118 @@
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Writes src to a file in a new temporary directory,
+// which the caller must remove.
+func writeSource(t *testing.T, src string) (dir string, filename string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "joker-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename = filepath.Join(dir, "source.joke")
+	if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return dir, filename
+}
+
+// Errors are printed with the source line they point to. Expected
+// output is checked up to the stack trace, FILE stands for the name
+// of the source file.
+func TestPrintError(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected string
+	}{
+		{"parse error", "(def x 1)\n(undefined-fn x)\n",
+			"FILE:2:2: Parse error: Unable to resolve symbol: undefined-fn\n" +
+				"  |\n2 | (undefined-fn x)\n  |  ^^^^^^^^^^^^\n"},
+		// Errors in core functions point to the call in user's code.
+		{"eval error", "(+ 1 (nth [] 5))\n",
+			"Eval error: Index 5 is out of bounds [0..-1]\n" +
+				"  --> FILE:1:6\n  |\n1 | (+ 1 (nth [] 5))\n  |      ^^^^^^^^^^\n"},
+		{"read error", "(def x \"\\q\")\n",
+			"FILE:1:10: Read error: Unsupported escape character: \\q\n" +
+				"  |\n1 | (def x \"\\q\")\n  |          ^\n"},
+		// Columns count characters, not bytes.
+		{"multi-byte characters", "(def s \"żółw\") (foo s)\n",
+			"FILE:1:17: Parse error: Unable to resolve symbol: foo, did you mean for?\n" +
+				"  |\n1 | (def s \"żółw\") (foo s)\n  |                 ^^^\n"},
+		// Tabs are kept, so carets line up with the source.
+		{"tabs", "\t(let [a 1]\n\t\t(bar a))\n",
+			"FILE:2:4: Parse error: Unable to resolve symbol: bar\n" +
+				"  |\n2 | \t\t(bar a))\n  | \t\t ^^^\n"},
+		// Unexpected end of file points past the last line.
+		{"end of file", "(def x (1 2\n",
+			"FILE:2:0: Read error: Unexpected end of file\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, filename := writeSource(t, test.src)
+			defer os.RemoveAll(dir)
+			_, err := NewInterpreter().EvalReader(strings.NewReader(test.src), filename)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			var b bytes.Buffer
+			PrintError(&b, err)
+			res := strings.Split(b.String(), "Stacktrace:\n")[0]
+			expected := strings.Replace(test.expected, "FILE", filename, -1)
+			if !strings.HasSuffix(res, expected) {
+				t.Errorf("expected output ending with\n%s\ngot\n%s", expected, res)
+			}
+		})
+	}
+}
+
+func TestSourceContext(t *testing.T) {
+	dir, filename := writeSource(t, "(def x 1)\n(foo\n  bar)\n")
+	defer os.RemoveAll(dir)
+	tests := []struct {
+		name     string
+		pos      Position
+		expected string
+	}{
+		{"single column", Position{startLine: 1, startColumn: 2, endLine: 1, endColumn: 2},
+			"  |\n1 | (def x 1)\n  |  ^\n"},
+		// Right after the end of the line, e.g. a missing delimiter.
+		{"end of line", Position{startLine: 1, startColumn: 10, endLine: 1, endColumn: 10},
+			"  |\n1 | (def x 1)\n  |          ^\n"},
+		{"span past end of line", Position{startLine: 1, startColumn: 8, endLine: 1, endColumn: 20},
+			"  |\n1 | (def x 1)\n  |        ^^\n"},
+		// Only the first line of a multi-line span is shown.
+		{"multiple lines", Position{startLine: 2, startColumn: 1, endLine: 3, endColumn: 6},
+			"  |\n2 | (foo\n  | ^^^^\n"},
+		{"column past end of line", Position{startLine: 1, startColumn: 11, endLine: 1, endColumn: 11}, ""},
+		{"line past end of file", Position{startLine: 4, startColumn: 1, endLine: 4, endColumn: 1}, ""},
+	}
+	for _, test := range tests {
+		test.pos.filename = &filename
+		if res := sourceContext(test.pos, false); res != test.expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", test.name, test.expected, res)
+		}
+	}
+	pos := Position{startLine: 1, startColumn: 2, endLine: 1, endColumn: 4, filename: &filename}
+	expected := colorBlue + "  |" + colorReset + "\n" +
+		colorBlue + "1 |" + colorReset + " (def x 1)\n" +
+		colorBlue + "  |" + colorReset + "  " + colorRed + "^^^" + colorReset + "\n"
+	if res := sourceContext(pos, true); res != expected {
+		t.Errorf("expected colored context\n%q\ngot\n%q", expected, res)
+	}
+	// Sources that are not files have no context.
+	name := "<string>"
+	pos.filename = &name
+	if res := sourceContext(pos, false); res != "" {
+		t.Errorf("expected no context for %s, got %q", name, res)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	Time struct {
+		InfoHolder
+		T time.Time
+	}
+)
+
+// Layouts accepted by #inst reader, from the most to the least specific.
+// Fractional seconds are accepted by time.Parse even though
+// the layouts don't mention them.
+var instLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+func MakeTime(t time.Time) Time {
+	return Time{T: t}
+}
+
+func ParseTime(s string) (Time, error) {
+	for _, layout := range instLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time{T: t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("Unrecognized date/time syntax: %s", s)
+}
+
+func (t Time) ToString(escape bool) string {
+	if escape {
+		return "#inst \"" + t.format() + "\""
+	}
+	return t.format()
+}
+
+func (t Time) format() string {
+	u := t.T.UTC()
+	if u.Nanosecond()%int(time.Millisecond) == 0 {
+		return u.Format("2006-01-02T15:04:05.000") + "-00:00"
+	}
+	return u.Format("2006-01-02T15:04:05.000000000") + "-00:00"
+}
+
+func (t Time) Print(w io.Writer, printReadably bool) {
+	fmt.Fprint(w, t.ToString(true))
+}
+
+func (t Time) Equals(other interface{}) bool {
+	switch other := other.(type) {
+	case Time:
+		return t.T.Equal(other.T)
+	default:
+		return false
+	}
+}
+
+func (t Time) GetType() *Type {
+	return TYPES["Time"]
+}
+
+func (t Time) Native() interface{} {
+	return t.T
+}
+
+func (t Time) Hash() uint32 {
+	h := getHash()
+	h.Write(uint32ToBytes(uint32(t.T.UnixNano())))
+	h.Write(uint32ToBytes(uint32(t.T.UnixNano() >> 32)))
+	return h.Sum32()
+}
+
+func (t Time) Compare(other Object) int {
+	t2 := AssertTime(other, "Cannot compare Time and "+other.GetType().ToString(false))
+	switch {
+	case t.T.Before(t2.T):
+		return -1
+	case t.T.After(t2.T):
+		return 1
+	}
+	return 0
+}
@@ -656,3 +656,150 @@ func EnsurePending(args []Object, index int) Pending {
     panic(RT.newArgTypeError(index, c, "Pending"))
   }
 }
+
+func AssertTime(obj Object, msg string) Time {
+  switch c := obj.(type) {
+  case Time:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "Time", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureTime(args []Object, index int) Time {
+  switch c := args[index].(type) {
+  case Time:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "Time"))
+  }
+}
+
+func AssertUUID(obj Object, msg string) UUID {
+  switch c := obj.(type) {
+  case UUID:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "UUID", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureUUID(args []Object, index int) UUID {
+  switch c := args[index].(type) {
+  case UUID:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "UUID"))
+  }
+}
+
+func AssertBlockingDeref(obj Object, msg string) BlockingDeref {
+  switch c := obj.(type) {
+  case BlockingDeref:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "BlockingDeref", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureBlockingDeref(args []Object, index int) BlockingDeref {
+  switch c := args[index].(type) {
+  case BlockingDeref:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "BlockingDeref"))
+  }
+}
+
+func AssertPromise(obj Object, msg string) *Promise {
+  switch c := obj.(type) {
+  case *Promise:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "Promise", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsurePromise(args []Object, index int) *Promise {
+  switch c := args[index].(type) {
+  case *Promise:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "Promise"))
+  }
+}
+
+func AssertChannel(obj Object, msg string) *Channel {
+  switch c := obj.(type) {
+  case *Channel:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "Channel", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureChannel(args []Object, index int) *Channel {
+  switch c := args[index].(type) {
+  case *Channel:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "Channel"))
+  }
+}
+
+func AssertWatchable(obj Object, msg string) Watchable {
+  switch c := obj.(type) {
+  case Watchable:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "Watchable", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureWatchable(args []Object, index int) Watchable {
+  switch c := args[index].(type) {
+  case Watchable:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "Watchable"))
+  }
+}
+
+func AssertProtocol(obj Object, msg string) *Protocol {
+  switch c := obj.(type) {
+  case *Protocol:
+    return c
+  default:
+    if msg == "" {
+      msg = fmt.Sprintf("Expected %s, got %s", "Protocol", obj.GetType().ToString(false))
+    }
+    panic(RT.NewError(msg))
+  }
+}
+
+func EnsureProtocol(args []Object, index int) *Protocol {
+  switch c := args[index].(type) {
+  case *Protocol:
+    return c
+  default:
+    panic(RT.newArgTypeError(index, c, "Protocol"))
+  }
+}
@@ -146,3 +146,13 @@ func (x *VectorRSeq) WithInfo(info *ObjectInfo) Object {
   x.info = info
   return x
 }
+
+func (x Time) WithInfo(info *ObjectInfo) Object {
+  x.info = info
+  return x
+}
+
+func (x UUID) WithInfo(info *ObjectInfo) Object {
+  x.info = info
+  return x
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+type (
+	UUID struct {
+		InfoHolder
+		b [16]byte
+	}
+)
+
+func ParseUUID(s string) (UUID, error) {
+	var res UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return res, fmt.Errorf("Invalid UUID string: %s", s)
+	}
+	h := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(res.b[:], []byte(h)); err != nil {
+		return res, fmt.Errorf("Invalid UUID string: %s", s)
+	}
+	return res, nil
+}
+
+// Returns random (version 4) UUID.
+func RandomUUID() UUID {
+	var res UUID
+	if _, err := rand.Read(res.b[:]); err != nil {
+		panic(RT.NewError(err.Error()))
+	}
+	res.b[6] = (res.b[6] & 0x0f) | 0x40
+	res.b[8] = (res.b[8] & 0x3f) | 0x80
+	return res
+}
+
+func (u UUID) ToString(escape bool) string {
+	if escape {
+		return "#uuid \"" + u.format() + "\""
+	}
+	return u.format()
+}
+
+func (u UUID) format() string {
+	h := hex.EncodeToString(u.b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func (u UUID) Print(w io.Writer, printReadably bool) {
+	fmt.Fprint(w, u.ToString(true))
+}
+
+func (u UUID) Equals(other interface{}) bool {
+	switch other := other.(type) {
+	case UUID:
+		return u.b == other.b
+	default:
+		return false
+	}
+}
+
+func (u UUID) GetType() *Type {
+	return TYPES["UUID"]
+}
+
+func (u UUID) Native() interface{} {
+	return u.format()
+}
+
+func (u UUID) Hash() uint32 {
+	h := getHash()
+	h.Write(u.b[:])
+	return h.Sum32()
+}
+
+func (u UUID) Compare(other Object) int {
+	u2 := AssertUUID(other, "Cannot compare UUID and "+other.GetType().ToString(false))
+	for i := range u.b {
+		switch {
+		case u.b[i] < u2.b[i]:
+			return -1
+		case u.b[i] > u2.b[i]:
+			return 1
+		}
+	}
+	return 0
+}
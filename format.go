@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	. "github.com/candid82/joker/core"
+)
+
+// Reads formatter options from :format key of .joker file
+// in user's home directory.
+func loadFormatOptions() *FormatOptions {
+	res := DefaultFormatOptions()
+	if config := readConfig(); config != nil {
+		if ok, formatConfig := config.Get(MakeKeyword("format")); ok {
+			if formatConfig, ok := formatConfig.(Map); ok {
+				res.Configure(formatConfig)
+			}
+		}
+	}
+	return res
+}
+
+func formatSource(data []byte, filename string, opts *FormatOptions) ([]byte, error) {
+	tree, err := ReadCST(NewReader(bufio.NewReader(bytes.NewReader(data)), filename))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(FormatSyntaxTree(tree, opts)), nil
+}
+
+// Formats files in place. With --check, only lists the files
+// that are not formatted. Returns false if any file
+// could not be formatted or (with --check) needs formatting.
+func format(args []string) bool {
+	check := false
+	if len(args) > 0 && args[0] == "--check" {
+		check = true
+		args = args[1:]
+	}
+	opts := loadFormatOptions()
+	if len(args) == 1 && args[0] == "--" {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			return false
+		}
+		res, err := formatSource(data, "<stdin>", opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return false
+		}
+		if check {
+			return bytes.Equal(data, res)
+		}
+		os.Stdout.Write(res)
+		return true
+	}
+	ok := true
+	for _, filename := range collectLintFiles(args) {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			ok = false
+			continue
+		}
+		res, err := formatSource(data, filename, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+			continue
+		}
+		if bytes.Equal(data, res) {
+			continue
+		}
+		if check {
+			fmt.Println(filename)
+			ok = false
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err == nil {
+			err = ioutil.WriteFile(filename, res, info.Mode())
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			ok = false
+		}
+	}
+	return ok
+}
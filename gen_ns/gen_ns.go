@@ -0,0 +1,407 @@
+// Generates Joker namespace from exported functions and constants
+// of a Go package. Usage:
+//
+//	go run gen_ns.go <go package> <joker namespace> <output file>
+//
+// Functions that take or return values of types that can't be
+// converted automatically are skipped (and reported to stderr).
+//
+// The output depends on the Go toolchain the generator runs with:
+// newer standard library packages have more functions (e.g. math.FMA
+// appeared in Go 1.14). The generated file records the Go version it
+// was generated with; regenerate it with the same version unless
+// the intent is to pick up new functions.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/doc"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type (
+	FnInfo struct {
+		Name     string
+		ProcName string
+		Arglist  string
+		Doc      string
+		Body     string
+	}
+	ConstInfo struct {
+		Name  string
+		Value string
+		Doc   string
+	}
+	NsInfo struct {
+		GoPackage   string
+		GoVersion   string
+		PackageName string
+		Namespace   string
+		NsVar       string
+		Doc         string
+		Fns         []FnInfo
+		Consts      []ConstInfo
+	}
+)
+
+var nsTemplate string = `// Generated by gen_ns from {{.GoPackage}} of {{.GoVersion}}. Don't modify manually!
+
+package {{.PackageName}}
+
+import (
+	"{{.GoPackage}}"
+
+	. "github.com/candid82/joker/core"
+)
+{{range .Fns}}
+var {{.ProcName}} Proc = func(args []Object) Object {
+{{.Body}}}
+{{end}}
+var {{.NsVar}} = GLOBAL_ENV.EnsureNamespace(MakeSymbol("{{.Namespace}}"))
+
+func init() {
+	{{.NsVar}}.ResetMeta(MakeMeta(nil, {{printf "%q" .Doc}}, "1.0"))
+{{- range .Consts}}
+	{{$.NsVar}}.InternVar("{{.Name}}", {{.Value}},
+		MakeMeta(nil, {{printf "%q" .Doc}}, "1.0"))
+{{- end}}
+{{- range .Fns}}
+	{{$.NsVar}}.InternVar("{{.Name}}", {{.ProcName}},
+		MakeMeta(
+			NewListFrom(NewVectorFrom({{.Arglist}})),
+			{{printf "%q" .Doc}}, "1.0"))
+{{- end}}
+}
+`
+
+func checkError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Converts Go name to Joker name: HasPrefix -> has-prefix,
+// IsNaN -> nan? (if the function returns bool), Log10 -> log10,
+// SqrtE -> sqrt-e.
+func jokerName(name string, predicate bool) string {
+	runes := []rune(name)
+	var b bytes.Buffer
+	wordStart := 0
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			lowerBefore := unicode.IsLower(prev) || unicode.IsDigit(prev)
+			lowerAfter := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			// Single trailing capital only starts a word after
+			// a word longer than two letters: SqrtE, but not NaN.
+			last := i+1 == len(runes)
+			if lowerBefore && (!last || i-wordStart > 2) || unicode.IsUpper(prev) && lowerAfter {
+				b.WriteRune('-')
+				wordStart = i
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	res := b.String()
+	if predicate {
+		res = strings.TrimPrefix(res, "is-") + "?"
+	}
+	return res
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+var intRanges = map[types.BasicKind][2]int64{
+	types.Int8:   {math.MinInt8, math.MaxInt8},
+	types.Int16:  {math.MinInt16, math.MaxInt16},
+	types.Int32:  {math.MinInt32, math.MaxInt32},
+	types.Uint8:  {0, math.MaxUint8},
+	types.Uint16: {0, math.MaxUint16},
+	types.Uint32: {0, math.MaxUint32},
+}
+
+// Returns Go expression that extracts argument at index
+// converted to type t.
+func argExpr(t types.Type, index int) (string, bool) {
+	basic, ok := t.(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch basic.Kind() {
+	case types.String:
+		return fmt.Sprintf("EnsureString(args, %d).S", index), true
+	case types.Bool:
+		return fmt.Sprintf("EnsureBool(args, %d).B", index), true
+	case types.Int:
+		return fmt.Sprintf("EnsureInt(args, %d).I", index), true
+	case types.Int64:
+		return fmt.Sprintf("int64(EnsureInt(args, %d).I)", index), true
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16, types.Uint32:
+		// Arguments that don't fit into the type raise an error
+		// instead of silently wrapping around.
+		r := intRanges[basic.Kind()]
+		return fmt.Sprintf("%s(EnsureIntInRange(args, %d, %d, %d, %q))", basic.Name(), index, r[0], r[1], basic.Name()), true
+	case types.Float64:
+		return fmt.Sprintf("EnsureNumber(args, %d).Double().D", index), true
+	case types.Float32:
+		return fmt.Sprintf("float32(EnsureNumber(args, %d).Double().D)", index), true
+	}
+	return "", false
+}
+
+// Returns Go expression that converts value of variable v
+// of type t to Joker object.
+func resultExpr(t types.Type, v string) (string, bool) {
+	if slice, ok := t.(*types.Slice); ok {
+		if _, ok := resultExpr(slice.Elem(), ""); ok {
+			return fmt.Sprintf("ToObject(%s)", v), true
+		}
+		return "", false
+	}
+	basic, ok := t.(*types.Basic)
+	if !ok {
+		return "", false
+	}
+	switch basic.Kind() {
+	case types.String:
+		return fmt.Sprintf("String{S: %s}", v), true
+	case types.Bool:
+		return fmt.Sprintf("Bool{B: %s}", v), true
+	case types.Int:
+		return fmt.Sprintf("Int{I: %s}", v), true
+	case types.Int8, types.Int16, types.Int32, types.Int64, types.Uint8, types.Uint16, types.Uint32:
+		return fmt.Sprintf("Int{I: int(%s)}", v), true
+	case types.Float64:
+		return fmt.Sprintf("Double{D: %s}", v), true
+	case types.Float32:
+		return fmt.Sprintf("Double{D: float64(%s)}", v), true
+	}
+	return "", false
+}
+
+// Parameter names are used as names of local variables,
+// so they must not shadow anything the generated code refers to.
+func localName(name string, index int, pkgName string) string {
+	switch name {
+	case "", "_":
+		return fmt.Sprintf("arg%d", index+1)
+	case "args", "res", "err", pkgName:
+		return name + "_"
+	}
+	return name
+}
+
+func generateFn(fn *types.Func, pkgName string, docText string) (FnInfo, error) {
+	sig := fn.Type().(*types.Signature)
+	if sig.Variadic() {
+		return FnInfo{}, fmt.Errorf("variadic functions are not supported")
+	}
+	params := sig.Params()
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "\tCheckArity(args, %d, %d)\n", params.Len(), params.Len())
+	names := make([]string, params.Len())
+	arglist := make([]string, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		p := params.At(i)
+		expr, ok := argExpr(p.Type(), i)
+		if !ok {
+			return FnInfo{}, fmt.Errorf("unsupported parameter type %s", p.Type())
+		}
+		names[i] = localName(p.Name(), i, pkgName)
+		arglist[i] = fmt.Sprintf("MakeSymbol(%q)", strings.TrimSuffix(names[i], "_"))
+		fmt.Fprintf(&body, "\t%s := %s\n", names[i], expr)
+	}
+	results := sig.Results()
+	var vars []string
+	var values []string
+	returnsError := false
+	predicate := false
+	single := results.Len() == 1 || results.Len() == 2 && isError(results.At(1).Type())
+	for i := 0; i < results.Len(); i++ {
+		t := results.At(i).Type()
+		if i == results.Len()-1 && isError(t) {
+			vars = append(vars, "err")
+			returnsError = true
+			continue
+		}
+		v := fmt.Sprintf("res%d", i)
+		if single {
+			v = "res"
+		}
+		expr, ok := resultExpr(t, v)
+		if !ok {
+			return FnInfo{}, fmt.Errorf("unsupported result type %s", t)
+		}
+		if b, ok := t.(*types.Basic); ok && b.Kind() == types.Bool && single {
+			predicate = true
+		}
+		vars = append(vars, v)
+		values = append(values, expr)
+	}
+	call := fmt.Sprintf("%s.%s(%s)", pkgName, fn.Name(), strings.Join(names, ", "))
+	if len(vars) > 0 {
+		call = strings.Join(vars, ", ") + " := " + call
+	}
+	fmt.Fprintf(&body, "\t%s\n", call)
+	if returnsError {
+		body.WriteString("\tif err != nil {\n\t\tpanic(RT.NewError(err.Error()))\n\t}\n")
+	}
+	switch len(values) {
+	case 0:
+		body.WriteString("\treturn NIL\n")
+	case 1:
+		fmt.Fprintf(&body, "\treturn %s\n", values[0])
+	default:
+		fmt.Fprintf(&body, "\treturn NewVectorFrom(%s)\n", strings.Join(values, ", "))
+	}
+	return FnInfo{
+		Name:     jokerName(fn.Name(), predicate),
+		ProcName: "proc" + fn.Name(),
+		Arglist:  strings.Join(arglist, ", "),
+		Doc:      strings.TrimSpace(docText),
+		Body:     body.String(),
+	}, nil
+}
+
+// Only constants that fit into Joker's Int on all platforms
+// and floating point constants are supported.
+func generateConst(c *types.Const, pkgName string, docText string) (ConstInfo, bool) {
+	val := c.Val()
+	var value string
+	switch val.Kind() {
+	case constant.Int:
+		i, exact := constant.Int64Val(val)
+		if !exact || i < math.MinInt32 || i > math.MaxInt32 {
+			return ConstInfo{}, false
+		}
+		value = fmt.Sprintf("Int{I: %s.%s}", pkgName, c.Name())
+	case constant.Float:
+		value = fmt.Sprintf("Double{D: %s.%s}", pkgName, c.Name())
+	case constant.String:
+		value = fmt.Sprintf("String{S: %s.%s}", pkgName, c.Name())
+	default:
+		return ConstInfo{}, false
+	}
+	return ConstInfo{
+		Name:  jokerName(c.Name(), false),
+		Value: value,
+		Doc:   strings.TrimSpace(docText),
+	}, true
+}
+
+// Collects doc comments of functions and constants.
+func packageDocs(path string) (string, map[string]string) {
+	bp, err := build.Import(path, "", 0)
+	checkError(err)
+	fset := token.NewFileSet()
+	isGoFile := func(info os.FileInfo) bool {
+		for _, name := range bp.GoFiles {
+			if name == info.Name() {
+				return true
+			}
+		}
+		return false
+	}
+	pkgs, err := parser.ParseDir(fset, bp.Dir, isGoFile, parser.ParseComments)
+	checkError(err)
+	docs := make(map[string]string)
+	pkg := pkgs[bp.Name]
+	if pkg == nil {
+		return "", docs
+	}
+	d := doc.New(pkg, path, 0)
+	addValues := func(values []*doc.Value) {
+		for _, v := range values {
+			for _, spec := range v.Decl.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					text := v.Doc
+					if c := spec.(*ast.ValueSpec).Doc; c != nil {
+						text = c.Text()
+					}
+					docs[name.Name] = text
+				}
+			}
+		}
+	}
+	addValues(d.Consts)
+	for _, f := range d.Funcs {
+		docs[f.Name] = f.Doc
+	}
+	for _, t := range d.Types {
+		addValues(t.Consts)
+		for _, f := range t.Funcs {
+			docs[f.Name] = f.Doc
+		}
+	}
+	return doc.Synopsis(d.Doc), docs
+}
+
+func main() {
+	if len(os.Args) != 4 {
+		fmt.Fprintln(os.Stderr, "Usage: gen_ns <go package> <joker namespace> <output file>")
+		os.Exit(1)
+	}
+	path, namespace, filename := os.Args[1], os.Args[2], os.Args[3]
+	pkg, err := importer.For("source", nil).Import(path)
+	checkError(err)
+	synopsis, docs := packageDocs(path)
+	packageName := namespace[strings.LastIndex(namespace, ".")+1:]
+	info := NsInfo{
+		GoPackage:   path,
+		GoVersion:   runtime.Version(),
+		PackageName: packageName,
+		Namespace:   namespace,
+		NsVar:       packageName + "Namespace",
+		Doc:         synopsis,
+	}
+	scope := pkg.Scope()
+	names := scope.Names()
+	sort.Strings(names)
+	for _, name := range names {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Func:
+			fn, err := generateFn(obj, pkg.Name(), docs[name])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping %s.%s: %s\n", path, name, err)
+				continue
+			}
+			info.Fns = append(info.Fns, fn)
+		case *types.Const:
+			if c, ok := generateConst(obj, pkg.Name(), docs[name]); ok {
+				info.Consts = append(info.Consts, c)
+			}
+		}
+	}
+	var b bytes.Buffer
+	tmpl := template.Must(template.New("ns").Parse(nsTemplate))
+	checkError(tmpl.Execute(&b, info))
+	src, err := format.Source(b.Bytes())
+	checkError(err)
+	f, err := os.Create(filename)
+	checkError(err)
+	defer f.Close()
+	f.Write(src)
+	fmt.Fprintf(os.Stderr, "Generated %s: %d functions, %d constants\n", filename, len(info.Fns), len(info.Consts))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"go/types"
+	"testing"
+)
+
+func TestJokerName(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate bool
+		expected  string
+	}{
+		{"HasPrefix", false, "has-prefix"},
+		{"Log10", false, "log10"},
+		{"SqrtE", false, "sqrt-e"},
+		{"Log2E", false, "log2-e"},
+		{"SqrtPi", false, "sqrt-pi"},
+		{"NaN", false, "nan"},
+		{"IsNaN", true, "nan?"},
+		{"Signbit", true, "signbit?"},
+		{"FMA", false, "fma"},
+		{"MaxInt8", false, "max-int8"},
+		{"RoundToEven", false, "round-to-even"},
+		{"E", false, "e"},
+	}
+	for _, test := range tests {
+		if res := jokerName(test.name, test.predicate); res != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, res)
+		}
+	}
+}
+
+func TestArgExpr(t *testing.T) {
+	tests := []struct {
+		kind     types.BasicKind
+		expected string
+	}{
+		{types.Int, `EnsureInt(args, 0).I`},
+		{types.Int64, `int64(EnsureInt(args, 0).I)`},
+		{types.Int8, `int8(EnsureIntInRange(args, 0, -128, 127, "int8"))`},
+		{types.Uint32, `uint32(EnsureIntInRange(args, 0, 0, 4294967295, "uint32"))`},
+		{types.Float32, `float32(EnsureNumber(args, 0).Double().D)`},
+	}
+	for _, test := range tests {
+		if res, ok := argExpr(types.Typ[test.kind], 0); !ok || res != test.expected {
+			t.Errorf("%s: expected %s, got %s", types.Typ[test.kind], test.expected, res)
+		}
+	}
+	if _, ok := argExpr(types.Typ[types.Uint64], 0); ok {
+		t.Errorf("expected uint64 to be unsupported")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/candid82/joker/core"
+)
+
+func TestLinterIsolatesFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "joker-lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"a.clj", "(ns a (:require [c.d :as d]))\n(def foo 1)\n(d/f foo)\n", ""},
+		{"b.clj", "(ns b)\n(inc foo)\n", "b.clj:2:6: Parse error: Unable to resolve symbol: foo, did you mean for?\n"},
+		{"c.clj", "(ns c (:require [c.d :as d]))\n", "c.clj:1:18: Parse warning: unused namespace c.d\n"},
+	}
+	for _, f := range files {
+		writeFile(t, filepath.Join(dir, f.name), f.content)
+	}
+	linter := NewLinter()
+	// Linting the files again must give the same results,
+	// since each file gets its own environment.
+	for i := 0; i < 2; i++ {
+		for _, f := range files {
+			var out bytes.Buffer
+			linter.Lint(filepath.Join(dir, f.name), CLJ, &out)
+			expected := f.expected
+			if expected != "" {
+				expected = filepath.Join(dir, expected)
+			}
+			if out.String() != expected {
+				t.Errorf("%s: expected %q, got %q", f.name, expected, out.String())
+			}
+		}
+	}
+}
@@ -2,14 +2,20 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
 
 	_ "github.com/candid82/joker/base64"
 	. "github.com/candid82/joker/core"
 	_ "github.com/candid82/joker/json"
+	_ "github.com/candid82/joker/math"
 	_ "github.com/candid82/joker/os"
 	_ "github.com/candid82/joker/string"
 	"gopkg.in/readline.v1"
@@ -55,10 +61,15 @@ func (ctx *ReplContext) PushException(exc Object) {
 
 func processFile(filename string, phase Phase) error {
 	var reader *Reader
+	if phase == EVAL {
+		loadNativeExtensions()
+	}
 	if filename == "--" {
+		LoadDataReaders(".")
 		reader = NewReader(bufio.NewReader(os.Stdin), "<stdin>")
 		filename = ""
 	} else {
+		LoadDataReaders(filepath.Dir(filename))
 		f, err := os.Open(filename)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Error: ", err)
@@ -85,10 +96,10 @@ func processReplCommand(reader *Reader, phase Phase, parseContext *ParseContext,
 			switch r := r.(type) {
 			case *ParseError:
 				replContext.PushException(r)
-				fmt.Fprintln(os.Stderr, r)
+				PrintError(os.Stderr, r)
 			case *EvalError:
 				replContext.PushException(r)
-				fmt.Fprintln(os.Stderr, r)
+				PrintError(os.Stderr, r)
 			case Error:
 				replContext.PushException(r)
 				fmt.Fprintln(os.Stderr, r)
@@ -131,6 +142,8 @@ func repl(phase Phase) {
 	fmt.Printf("Welcome to joker %s. Use ctrl-c to exit.\n", VERSION)
 	parseContext := &ParseContext{GlobalEnv: GLOBAL_ENV}
 	replContext := NewReplContext(parseContext.GlobalEnv)
+	LoadDataReaders(".")
+	loadNativeExtensions()
 
 	rl, err := readline.New("")
 	if err != nil {
@@ -148,28 +161,6 @@ func repl(phase Phase) {
 	}
 }
 
-func makeDialectKeyword(dialect Dialect) Keyword {
-	switch dialect {
-	case EDN:
-		return MakeKeyword("clj")
-	case CLJ:
-		return MakeKeyword("clj")
-	case CLJS:
-		return MakeKeyword("cljs")
-	default:
-		return MakeKeyword("joker ")
-	}
-}
-
-func configureLinterMode(dialect Dialect) {
-	LINTER_MODE = true
-	DIALECT = dialect
-	lm, _ := GLOBAL_ENV.Resolve(MakeSymbol("joker.core/*linter-mode*"))
-	lm.Value = Bool{B: true}
-	GLOBAL_ENV.Features = GLOBAL_ENV.Features.Disjoin(MakeKeyword("joker")).Conj(makeDialectKeyword(dialect)).(Set)
-	ProcessLinterData(dialect)
-}
-
 func detectDialect(filename string) Dialect {
 	switch {
 	case strings.HasSuffix(filename, ".edn"):
@@ -187,12 +178,147 @@ func lintFile(filename string, dialect Dialect) {
 	if dialect == EDN {
 		phase = READ
 	}
-	configureLinterMode(dialect)
+	ConfigureLinterMode(dialect)
 	if processFile(filename, phase) == nil {
 		WarnOnUnusedNamespaces()
 	}
 }
 
+func isLintable(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".clj", ".cljs", ".cljc", ".joke", ".edn":
+		return true
+	}
+	return false
+}
+
+// Expands directories into the list of lintable files they contain.
+func collectLintFiles(paths []string) []string {
+	var res []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			res = append(res, path)
+			continue
+		}
+		filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && isLintable(filename) {
+				res = append(res, filename)
+			}
+			return nil
+		})
+	}
+	return res
+}
+
+func lintDialect(mode string, filename string) Dialect {
+	switch mode {
+	case "--lintclj":
+		return CLJ
+	case "--lintcljs":
+		return CLJS
+	case "--lintjoker":
+		return JOKER
+	case "--lintedn":
+		return EDN
+	default:
+		return detectDialect(filename)
+	}
+}
+
+// Every file is linted in its own environment (see Linter),
+// so definitions in one file don't affect the others.
+func lintFiles(mode string, files []string, out io.Writer) {
+	linter := NewLinter()
+	for _, filename := range files {
+		var output bytes.Buffer
+		linter.Lint(filename, lintDialect(mode, filename), &output)
+		out.Write(output.Bytes())
+	}
+}
+
+func lint(mode string, args []string) {
+	since := ""
+	if len(args) > 1 && args[0] == "--since" {
+		since = args[1]
+		args = args[2:]
+	}
+	files := collectLintFiles(args)
+	if since == "" && len(files) == 1 {
+		lintFile(files[0], lintDialect(mode, files[0]))
+		return
+	}
+	if since == "" {
+		lintFiles(mode, files, os.Stderr)
+		return
+	}
+	changed, err := changedLines(since, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: ", err)
+		return
+	}
+	filter := newChangedLinesFilter(os.Stderr, changed)
+	lintFiles(mode, files, filter)
+	filter.Flush()
+}
+
+func printMetricsTable(metrics []FnMetrics) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tLOCATION\tLINES\tDEPTH\tCOMPLEXITY\tARITIES")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "%s\t%s:%d\t%d\t%d\t%d\t%d\n", m.Name, m.File, m.Line, m.Lines, m.Depth, m.Complexity, m.Arities)
+	}
+	w.Flush()
+}
+
+// Returns false if any of the files can't be read
+// or any of the thresholds is exceeded.
+func metrics(args []string) bool {
+	flags := flag.NewFlagSet("metrics", flag.ExitOnError)
+	format := flags.String("format", "table", "output format: table or json")
+	var thresholds MetricsThresholds
+	flags.IntVar(&thresholds.MaxLines, "max-lines", 0, "warn about functions longer than this many lines")
+	flags.IntVar(&thresholds.MaxDepth, "max-depth", 0, "warn about functions nested deeper than this")
+	flags.IntVar(&thresholds.MaxComplexity, "max-complexity", 0, "warn about functions with higher cyclomatic complexity")
+	flags.IntVar(&thresholds.MaxArities, "max-arities", 0, "warn about functions with more arities")
+	flags.Parse(args)
+
+	// Metrics are collected from read forms only, linter mode
+	// just makes the reader more forgiving.
+	LINTER_MODE = true
+	ok := true
+	res := []FnMetrics{}
+	for _, filename := range flags.Args() {
+		f, err := os.Open(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: ", err)
+			ok = false
+			continue
+		}
+		DIALECT = detectDialect(filename)
+		m, err := CollectMetrics(NewReader(bufio.NewReader(f), filename))
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			ok = false
+		}
+		res = append(res, m...)
+	}
+	for i := range res {
+		if res[i].WarnOnThresholds(&thresholds) {
+			ok = false
+		}
+	}
+	switch *format {
+	case "json":
+		b, _ := json.MarshalIndent(res, "", "  ")
+		fmt.Println(string(b))
+	default:
+		printMetricsTable(res)
+	}
+	return ok
+}
+
 func main() {
 	GLOBAL_ENV.FindNamespace(MakeSymbol("user")).ReferAll(GLOBAL_ENV.CoreNamespace)
 	if len(os.Args) == 1 {
@@ -212,17 +338,16 @@ func main() {
 		processFile(os.Args[2], READ)
 	case "--parse":
 		processFile(os.Args[2], PARSE)
-	case "--lint":
-		dialect := detectDialect(os.Args[2])
-		lintFile(os.Args[2], dialect)
-	case "--lintclj":
-		lintFile(os.Args[2], CLJ)
-	case "--lintcljs":
-		lintFile(os.Args[2], CLJS)
-	case "--lintjoker":
-		lintFile(os.Args[2], JOKER)
-	case "--lintedn":
-		lintFile(os.Args[2], EDN)
+	case "--lint", "--lintclj", "--lintcljs", "--lintjoker", "--lintedn":
+		lint(os.Args[1], os.Args[2:])
+	case "--metrics":
+		if !metrics(os.Args[2:]) {
+			os.Exit(1)
+		}
+	case "--format":
+		if !format(os.Args[2:]) {
+			os.Exit(1)
+		}
 	default:
 		processFile(os.Args[1], EVAL)
 	}
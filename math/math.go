@@ -0,0 +1,3 @@
+//go:generate go run ../gen_ns/gen_ns.go math joker.math math_gen.go
+
+package math
@@ -0,0 +1,792 @@
+// Generated by gen_ns from math of go1.27.1. Don't modify manually!
+
+package math
+
+import (
+	"math"
+
+	. "github.com/candid82/joker/core"
+)
+
+var procAbs Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Abs(x)
+	return Double{D: res}
+}
+
+var procAcos Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Acos(x)
+	return Double{D: res}
+}
+
+var procAcosh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Acosh(x)
+	return Double{D: res}
+}
+
+var procAsin Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Asin(x)
+	return Double{D: res}
+}
+
+var procAsinh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Asinh(x)
+	return Double{D: res}
+}
+
+var procAtan Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Atan(x)
+	return Double{D: res}
+}
+
+var procAtan2 Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	y := EnsureNumber(args, 0).Double().D
+	x := EnsureNumber(args, 1).Double().D
+	res := math.Atan2(y, x)
+	return Double{D: res}
+}
+
+var procAtanh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Atanh(x)
+	return Double{D: res}
+}
+
+var procCbrt Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Cbrt(x)
+	return Double{D: res}
+}
+
+var procCeil Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Ceil(x)
+	return Double{D: res}
+}
+
+var procCopysign Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	f := EnsureNumber(args, 0).Double().D
+	sign := EnsureNumber(args, 1).Double().D
+	res := math.Copysign(f, sign)
+	return Double{D: res}
+}
+
+var procCos Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Cos(x)
+	return Double{D: res}
+}
+
+var procCosh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Cosh(x)
+	return Double{D: res}
+}
+
+var procDim Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Dim(x, y)
+	return Double{D: res}
+}
+
+var procErf Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Erf(x)
+	return Double{D: res}
+}
+
+var procErfc Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Erfc(x)
+	return Double{D: res}
+}
+
+var procErfcinv Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Erfcinv(x)
+	return Double{D: res}
+}
+
+var procErfinv Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Erfinv(x)
+	return Double{D: res}
+}
+
+var procExp Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Exp(x)
+	return Double{D: res}
+}
+
+var procExp2 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Exp2(x)
+	return Double{D: res}
+}
+
+var procExpm1 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Expm1(x)
+	return Double{D: res}
+}
+
+var procFMA Proc = func(args []Object) Object {
+	CheckArity(args, 3, 3)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	z := EnsureNumber(args, 2).Double().D
+	res := math.FMA(x, y, z)
+	return Double{D: res}
+}
+
+var procFloat32bits Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	f := float32(EnsureNumber(args, 0).Double().D)
+	res := math.Float32bits(f)
+	return Int{I: int(res)}
+}
+
+var procFloat32frombits Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	b := uint32(EnsureIntInRange(args, 0, 0, 4294967295, "uint32"))
+	res := math.Float32frombits(b)
+	return Double{D: float64(res)}
+}
+
+var procFloor Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Floor(x)
+	return Double{D: res}
+}
+
+var procFrexp Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	f := EnsureNumber(args, 0).Double().D
+	res0, res1 := math.Frexp(f)
+	return NewVectorFrom(Double{D: res0}, Int{I: res1})
+}
+
+var procGamma Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Gamma(x)
+	return Double{D: res}
+}
+
+var procHypot Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	p := EnsureNumber(args, 0).Double().D
+	q := EnsureNumber(args, 1).Double().D
+	res := math.Hypot(p, q)
+	return Double{D: res}
+}
+
+var procIlogb Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Ilogb(x)
+	return Int{I: res}
+}
+
+var procInf Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	sign := EnsureInt(args, 0).I
+	res := math.Inf(sign)
+	return Double{D: res}
+}
+
+var procIsInf Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	f := EnsureNumber(args, 0).Double().D
+	sign := EnsureInt(args, 1).I
+	res := math.IsInf(f, sign)
+	return Bool{B: res}
+}
+
+var procIsNaN Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	f := EnsureNumber(args, 0).Double().D
+	res := math.IsNaN(f)
+	return Bool{B: res}
+}
+
+var procJ0 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.J0(x)
+	return Double{D: res}
+}
+
+var procJ1 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.J1(x)
+	return Double{D: res}
+}
+
+var procJn Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	n := EnsureInt(args, 0).I
+	x := EnsureNumber(args, 1).Double().D
+	res := math.Jn(n, x)
+	return Double{D: res}
+}
+
+var procLdexp Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	frac := EnsureNumber(args, 0).Double().D
+	exp := EnsureInt(args, 1).I
+	res := math.Ldexp(frac, exp)
+	return Double{D: res}
+}
+
+var procLgamma Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res0, res1 := math.Lgamma(x)
+	return NewVectorFrom(Double{D: res0}, Int{I: res1})
+}
+
+var procLog Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Log(x)
+	return Double{D: res}
+}
+
+var procLog10 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Log10(x)
+	return Double{D: res}
+}
+
+var procLog1p Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Log1p(x)
+	return Double{D: res}
+}
+
+var procLog2 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Log2(x)
+	return Double{D: res}
+}
+
+var procLogb Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Logb(x)
+	return Double{D: res}
+}
+
+var procMax Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Max(x, y)
+	return Double{D: res}
+}
+
+var procMin Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Min(x, y)
+	return Double{D: res}
+}
+
+var procMod Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Mod(x, y)
+	return Double{D: res}
+}
+
+var procModf Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	f := EnsureNumber(args, 0).Double().D
+	res0, res1 := math.Modf(f)
+	return NewVectorFrom(Double{D: res0}, Double{D: res1})
+}
+
+var procNaN Proc = func(args []Object) Object {
+	CheckArity(args, 0, 0)
+	res := math.NaN()
+	return Double{D: res}
+}
+
+var procNextafter Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Nextafter(x, y)
+	return Double{D: res}
+}
+
+var procNextafter32 Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := float32(EnsureNumber(args, 0).Double().D)
+	y := float32(EnsureNumber(args, 1).Double().D)
+	res := math.Nextafter32(x, y)
+	return Double{D: float64(res)}
+}
+
+var procPow Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Pow(x, y)
+	return Double{D: res}
+}
+
+var procPow10 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	n := EnsureInt(args, 0).I
+	res := math.Pow10(n)
+	return Double{D: res}
+}
+
+var procRemainder Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	x := EnsureNumber(args, 0).Double().D
+	y := EnsureNumber(args, 1).Double().D
+	res := math.Remainder(x, y)
+	return Double{D: res}
+}
+
+var procRound Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Round(x)
+	return Double{D: res}
+}
+
+var procRoundToEven Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.RoundToEven(x)
+	return Double{D: res}
+}
+
+var procSignbit Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Signbit(x)
+	return Bool{B: res}
+}
+
+var procSin Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Sin(x)
+	return Double{D: res}
+}
+
+var procSincos Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res0, res1 := math.Sincos(x)
+	return NewVectorFrom(Double{D: res0}, Double{D: res1})
+}
+
+var procSinh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Sinh(x)
+	return Double{D: res}
+}
+
+var procSqrt Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Sqrt(x)
+	return Double{D: res}
+}
+
+var procTan Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Tan(x)
+	return Double{D: res}
+}
+
+var procTanh Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Tanh(x)
+	return Double{D: res}
+}
+
+var procTrunc Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Trunc(x)
+	return Double{D: res}
+}
+
+var procY0 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Y0(x)
+	return Double{D: res}
+}
+
+var procY1 Proc = func(args []Object) Object {
+	CheckArity(args, 1, 1)
+	x := EnsureNumber(args, 0).Double().D
+	res := math.Y1(x)
+	return Double{D: res}
+}
+
+var procYn Proc = func(args []Object) Object {
+	CheckArity(args, 2, 2)
+	n := EnsureInt(args, 0).I
+	x := EnsureNumber(args, 1).Double().D
+	res := math.Yn(n, x)
+	return Double{D: res}
+}
+
+var mathNamespace = GLOBAL_ENV.EnsureNamespace(MakeSymbol("joker.math"))
+
+func init() {
+	mathNamespace.ResetMeta(MakeMeta(nil, "Package math provides basic constants and mathematical functions.", "1.0"))
+	mathNamespace.InternVar("e", Double{D: math.E},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("ln10", Double{D: math.Ln10},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("ln2", Double{D: math.Ln2},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("log10-e", Double{D: math.Log10E},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("log2-e", Double{D: math.Log2E},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("max-float32", Double{D: math.MaxFloat32},
+		MakeMeta(nil, "Floating-point limit values.\nMax is the largest finite value representable by the type.\nSmallestNonzero is the smallest positive, non-zero value representable by the type.", "1.0"))
+	mathNamespace.InternVar("max-float64", Double{D: math.MaxFloat64},
+		MakeMeta(nil, "Floating-point limit values.\nMax is the largest finite value representable by the type.\nSmallestNonzero is the smallest positive, non-zero value representable by the type.", "1.0"))
+	mathNamespace.InternVar("max-int16", Int{I: math.MaxInt16},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("max-int32", Int{I: math.MaxInt32},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("max-int8", Int{I: math.MaxInt8},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("max-uint16", Int{I: math.MaxUint16},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("max-uint8", Int{I: math.MaxUint8},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("min-int16", Int{I: math.MinInt16},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("min-int32", Int{I: math.MinInt32},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("min-int8", Int{I: math.MinInt8},
+		MakeMeta(nil, "Integer limit values.", "1.0"))
+	mathNamespace.InternVar("phi", Double{D: math.Phi},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("pi", Double{D: math.Pi},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("smallest-nonzero-float32", Double{D: math.SmallestNonzeroFloat32},
+		MakeMeta(nil, "Floating-point limit values.\nMax is the largest finite value representable by the type.\nSmallestNonzero is the smallest positive, non-zero value representable by the type.", "1.0"))
+	mathNamespace.InternVar("smallest-nonzero-float64", Double{D: math.SmallestNonzeroFloat64},
+		MakeMeta(nil, "Floating-point limit values.\nMax is the largest finite value representable by the type.\nSmallestNonzero is the smallest positive, non-zero value representable by the type.", "1.0"))
+	mathNamespace.InternVar("sqrt2", Double{D: math.Sqrt2},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("sqrt-e", Double{D: math.SqrtE},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("sqrt-phi", Double{D: math.SqrtPhi},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("sqrt-pi", Double{D: math.SqrtPi},
+		MakeMeta(nil, "Mathematical constants.", "1.0"))
+	mathNamespace.InternVar("abs", procAbs,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Abs returns the absolute value of x.\n\nSpecial cases are:\n\n\tAbs(±Inf) = +Inf\n\tAbs(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("acos", procAcos,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Acos returns the arccosine, in radians, of x.\n\nSpecial case is:\n\n\tAcos(x) = NaN if x < -1 or x > 1", "1.0"))
+	mathNamespace.InternVar("acosh", procAcosh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Acosh returns the inverse hyperbolic cosine of x.\n\nSpecial cases are:\n\n\tAcosh(+Inf) = +Inf\n\tAcosh(x) = NaN if x < 1\n\tAcosh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("asin", procAsin,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Asin returns the arcsine, in radians, of x.\n\nSpecial cases are:\n\n\tAsin(±0) = ±0\n\tAsin(x) = NaN if x < -1 or x > 1", "1.0"))
+	mathNamespace.InternVar("asinh", procAsinh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Asinh returns the inverse hyperbolic sine of x.\n\nSpecial cases are:\n\n\tAsinh(±0) = ±0\n\tAsinh(±Inf) = ±Inf\n\tAsinh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("atan", procAtan,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Atan returns the arctangent, in radians, of x.\n\nSpecial cases are:\n\n\tAtan(±0) = ±0\n\tAtan(±Inf) = ±Pi/2", "1.0"))
+	mathNamespace.InternVar("atan2", procAtan2,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("y"), MakeSymbol("x"))),
+			"Atan2 returns the arc tangent of y/x, using\nthe signs of the two to determine the quadrant\nof the return value.\n\nSpecial cases are (in order):\n\n\tAtan2(y, NaN) = NaN\n\tAtan2(NaN, x) = NaN\n\tAtan2(+0, x>=0) = +0\n\tAtan2(-0, x>=0) = -0\n\tAtan2(+0, x<=-0) = +Pi\n\tAtan2(-0, x<=-0) = -Pi\n\tAtan2(y>0, 0) = +Pi/2\n\tAtan2(y<0, 0) = -Pi/2\n\tAtan2(+Inf, +Inf) = +Pi/4\n\tAtan2(-Inf, +Inf) = -Pi/4\n\tAtan2(+Inf, -Inf) = 3Pi/4\n\tAtan2(-Inf, -Inf) = -3Pi/4\n\tAtan2(y, +Inf) = 0\n\tAtan2(y>0, -Inf) = +Pi\n\tAtan2(y<0, -Inf) = -Pi\n\tAtan2(+Inf, x) = +Pi/2\n\tAtan2(-Inf, x) = -Pi/2", "1.0"))
+	mathNamespace.InternVar("atanh", procAtanh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Atanh returns the inverse hyperbolic tangent of x.\n\nSpecial cases are:\n\n\tAtanh(1) = +Inf\n\tAtanh(±0) = ±0\n\tAtanh(-1) = -Inf\n\tAtanh(x) = NaN if x < -1 or x > 1\n\tAtanh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("cbrt", procCbrt,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Cbrt returns the cube root of x.\n\nSpecial cases are:\n\n\tCbrt(±0) = ±0\n\tCbrt(±Inf) = ±Inf\n\tCbrt(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("ceil", procCeil,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Ceil returns the least integer value greater than or equal to x.\n\nSpecial cases are:\n\n\tCeil(±0) = ±0\n\tCeil(±Inf) = ±Inf\n\tCeil(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("copysign", procCopysign,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"), MakeSymbol("sign"))),
+			"Copysign returns a value with the magnitude of f\nand the sign of sign.", "1.0"))
+	mathNamespace.InternVar("cos", procCos,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Cos returns the cosine of the radian argument x.\n\nSpecial cases are:\n\n\tCos(±Inf) = NaN\n\tCos(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("cosh", procCosh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Cosh returns the hyperbolic cosine of x.\n\nSpecial cases are:\n\n\tCosh(±0) = 1\n\tCosh(±Inf) = +Inf\n\tCosh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("dim", procDim,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Dim returns the maximum of x-y or 0.\n\nSpecial cases are:\n\n\tDim(+Inf, +Inf) = NaN\n\tDim(-Inf, -Inf) = NaN\n\tDim(x, NaN) = Dim(NaN, x) = NaN", "1.0"))
+	mathNamespace.InternVar("erf", procErf,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Erf returns the error function of x.\n\nSpecial cases are:\n\n\tErf(+Inf) = 1\n\tErf(-Inf) = -1\n\tErf(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("erfc", procErfc,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Erfc returns the complementary error function of x.\n\nSpecial cases are:\n\n\tErfc(+Inf) = 0\n\tErfc(-Inf) = 2\n\tErfc(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("erfcinv", procErfcinv,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Erfcinv returns the inverse of [Erfc](x).\n\nSpecial cases are:\n\n\tErfcinv(0) = +Inf\n\tErfcinv(2) = -Inf\n\tErfcinv(x) = NaN if x < 0 or x > 2\n\tErfcinv(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("erfinv", procErfinv,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Erfinv returns the inverse error function of x.\n\nSpecial cases are:\n\n\tErfinv(1) = +Inf\n\tErfinv(-1) = -Inf\n\tErfinv(x) = NaN if x < -1 or x > 1\n\tErfinv(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("exp", procExp,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Exp returns e**x, the base-e exponential of x.\n\nSpecial cases are:\n\n\tExp(+Inf) = +Inf\n\tExp(NaN) = NaN\n\nVery large values overflow to 0 or +Inf.\nVery small values underflow to 1.", "1.0"))
+	mathNamespace.InternVar("exp2", procExp2,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Exp2 returns 2**x, the base-2 exponential of x.\n\nSpecial cases are the same as [Exp].", "1.0"))
+	mathNamespace.InternVar("expm1", procExpm1,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Expm1 returns e**x - 1, the base-e exponential of x minus 1.\nIt is more accurate than [Exp](x) - 1 when x is near zero.\n\nSpecial cases are:\n\n\tExpm1(+Inf) = +Inf\n\tExpm1(-Inf) = -1\n\tExpm1(NaN) = NaN\n\nVery large values overflow to -1 or +Inf.", "1.0"))
+	mathNamespace.InternVar("fma", procFMA,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"), MakeSymbol("z"))),
+			"FMA returns x * y + z, computed with only one rounding.\n(That is, FMA returns the fused multiply-add of x, y, and z.)", "1.0"))
+	mathNamespace.InternVar("float32bits", procFloat32bits,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"))),
+			"Float32bits returns the IEEE 754 binary representation of f,\nwith the sign bit of f and the result in the same bit position.\nFloat32bits(Float32frombits(x)) == x.", "1.0"))
+	mathNamespace.InternVar("float32frombits", procFloat32frombits,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("b"))),
+			"Float32frombits returns the floating-point number corresponding\nto the IEEE 754 binary representation b, with the sign bit of b\nand the result in the same bit position.\nFloat32frombits(Float32bits(x)) == x.", "1.0"))
+	mathNamespace.InternVar("floor", procFloor,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Floor returns the greatest integer value less than or equal to x.\n\nSpecial cases are:\n\n\tFloor(±0) = ±0\n\tFloor(±Inf) = ±Inf\n\tFloor(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("frexp", procFrexp,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"))),
+			"Frexp breaks f into a normalized fraction\nand an integral power of two.\nIt returns frac and exp satisfying f == frac × 2**exp,\nwith the absolute value of frac in the interval [½, 1).\n\nSpecial cases are:\n\n\tFrexp(±0) = ±0, 0\n\tFrexp(±Inf) = ±Inf, 0\n\tFrexp(NaN) = NaN, 0", "1.0"))
+	mathNamespace.InternVar("gamma", procGamma,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Gamma returns the Gamma function of x.\n\nSpecial cases are:\n\n\tGamma(+Inf) = +Inf\n\tGamma(+0) = +Inf\n\tGamma(-0) = -Inf\n\tGamma(x) = NaN for integer x < 0\n\tGamma(-Inf) = NaN\n\tGamma(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("hypot", procHypot,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("p"), MakeSymbol("q"))),
+			"Hypot returns [Sqrt](p*p + q*q), taking care to avoid\nunnecessary overflow and underflow.\n\nSpecial cases are:\n\n\tHypot(±Inf, q) = +Inf\n\tHypot(p, ±Inf) = +Inf\n\tHypot(NaN, q) = NaN\n\tHypot(p, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("ilogb", procIlogb,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Ilogb returns the binary exponent of x as an integer.\n\nSpecial cases are:\n\n\tIlogb(±Inf) = MaxInt32\n\tIlogb(0) = MinInt32\n\tIlogb(NaN) = MaxInt32", "1.0"))
+	mathNamespace.InternVar("inf", procInf,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("sign"))),
+			"Inf returns positive infinity if sign >= 0, negative infinity if sign < 0.", "1.0"))
+	mathNamespace.InternVar("inf?", procIsInf,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"), MakeSymbol("sign"))),
+			"IsInf reports whether f is an infinity, according to sign.\nIf sign > 0, IsInf reports whether f is positive infinity.\nIf sign < 0, IsInf reports whether f is negative infinity.\nIf sign == 0, IsInf reports whether f is either infinity.", "1.0"))
+	mathNamespace.InternVar("nan?", procIsNaN,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"))),
+			"IsNaN reports whether f is an IEEE 754 “not-a-number” value.", "1.0"))
+	mathNamespace.InternVar("j0", procJ0,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"J0 returns the order-zero Bessel function of the first kind.\n\nSpecial cases are:\n\n\tJ0(±Inf) = 0\n\tJ0(0) = 1\n\tJ0(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("j1", procJ1,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"J1 returns the order-one Bessel function of the first kind.\n\nSpecial cases are:\n\n\tJ1(±Inf) = 0\n\tJ1(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("jn", procJn,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("n"), MakeSymbol("x"))),
+			"Jn returns the order-n Bessel function of the first kind.\n\nSpecial cases are:\n\n\tJn(n, ±Inf) = 0\n\tJn(n, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("ldexp", procLdexp,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("frac"), MakeSymbol("exp"))),
+			"Ldexp is the inverse of [Frexp].\nIt returns frac × 2**exp.\n\nSpecial cases are:\n\n\tLdexp(±0, exp) = ±0\n\tLdexp(±Inf, exp) = ±Inf\n\tLdexp(NaN, exp) = NaN", "1.0"))
+	mathNamespace.InternVar("lgamma", procLgamma,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Lgamma returns the natural logarithm and sign (-1 or +1) of [Gamma](x).\n\nSpecial cases are:\n\n\tLgamma(+Inf) = +Inf\n\tLgamma(0) = +Inf\n\tLgamma(-integer) = +Inf\n\tLgamma(-Inf) = -Inf\n\tLgamma(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("log", procLog,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Log returns the natural logarithm of x.\n\nSpecial cases are:\n\n\tLog(+Inf) = +Inf\n\tLog(0) = -Inf\n\tLog(x < 0) = NaN\n\tLog(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("log10", procLog10,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Log10 returns the decimal logarithm of x.\nThe special cases are the same as for [Log].", "1.0"))
+	mathNamespace.InternVar("log1p", procLog1p,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Log1p returns the natural logarithm of 1 plus its argument x.\nIt is more accurate than [Log](1 + x) when x is near zero.\n\nSpecial cases are:\n\n\tLog1p(+Inf) = +Inf\n\tLog1p(±0) = ±0\n\tLog1p(-1) = -Inf\n\tLog1p(x < -1) = NaN\n\tLog1p(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("log2", procLog2,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Log2 returns the binary logarithm of x.\nThe special cases are the same as for [Log].", "1.0"))
+	mathNamespace.InternVar("logb", procLogb,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Logb returns the binary exponent of x.\n\nSpecial cases are:\n\n\tLogb(±Inf) = +Inf\n\tLogb(0) = -Inf\n\tLogb(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("max", procMax,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Max returns the larger of x or y.\n\nSpecial cases are:\n\n\tMax(x, +Inf) = Max(+Inf, x) = +Inf\n\tMax(x, NaN) = Max(NaN, x) = NaN\n\tMax(+0, ±0) = Max(±0, +0) = +0\n\tMax(-0, -0) = -0\n\nNote that this differs from the built-in function max when called\nwith NaN and +Inf.", "1.0"))
+	mathNamespace.InternVar("min", procMin,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Min returns the smaller of x or y.\n\nSpecial cases are:\n\n\tMin(x, -Inf) = Min(-Inf, x) = -Inf\n\tMin(x, NaN) = Min(NaN, x) = NaN\n\tMin(-0, ±0) = Min(±0, -0) = -0\n\nNote that this differs from the built-in function min when called\nwith NaN and -Inf.", "1.0"))
+	mathNamespace.InternVar("mod", procMod,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Mod returns the floating-point remainder of x/y.\nThe magnitude of the result is less than y and its\nsign agrees with that of x.\n\nSpecial cases are:\n\n\tMod(±Inf, y) = NaN\n\tMod(NaN, y) = NaN\n\tMod(x, 0) = NaN\n\tMod(x, ±Inf) = x\n\tMod(x, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("modf", procModf,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("f"))),
+			"Modf returns integer and fractional floating-point numbers\nthat sum to f. Both values have the same sign as f.\n\nSpecial cases are:\n\n\tModf(±Inf) = ±Inf, NaN\n\tModf(NaN) = NaN, NaN", "1.0"))
+	mathNamespace.InternVar("nan", procNaN,
+		MakeMeta(
+			NewListFrom(NewVectorFrom()),
+			"NaN returns an IEEE 754 “not-a-number” value.", "1.0"))
+	mathNamespace.InternVar("nextafter", procNextafter,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Nextafter returns the next representable float64 value after x towards y.\n\nSpecial cases are:\n\n\tNextafter(x, y)   = x when x == y\n\tNextafter(0, y)   = ±SmallestNonzeroFloat64 towards y, for y ≠ 0\n\tNextafter(NaN, y) = NaN\n\tNextafter(x, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("nextafter32", procNextafter32,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Nextafter32 returns the next representable float32 value after x towards y.\n\nSpecial cases are:\n\n\tNextafter32(x, y)   = x when x == y\n\tNextafter32(0, y)   = ±SmallestNonzeroFloat32 towards y, for y ≠ 0\n\tNextafter32(NaN, y) = NaN\n\tNextafter32(x, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("pow", procPow,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Pow returns x**y, the base-x exponential of y.\n\nSpecial cases are (in order):\n\n\tPow(x, ±0) = 1 for any x\n\tPow(1, y) = 1 for any y\n\tPow(x, 1) = x for any x\n\tPow(NaN, y) = NaN\n\tPow(x, NaN) = NaN\n\tPow(±0, y) = ±Inf for y an odd integer < 0\n\tPow(±0, -Inf) = +Inf\n\tPow(±0, +Inf) = +0\n\tPow(±0, y) = +Inf for finite y < 0 and not an odd integer\n\tPow(±0, y) = ±0 for y an odd integer > 0\n\tPow(±0, y) = +0 for finite y > 0 and not an odd integer\n\tPow(-1, ±Inf) = 1\n\tPow(x, +Inf) = +Inf for |x| > 1\n\tPow(x, -Inf) = +0 for |x| > 1\n\tPow(x, +Inf) = +0 for |x| < 1\n\tPow(x, -Inf) = +Inf for |x| < 1\n\tPow(+Inf, y) = +Inf for y > 0\n\tPow(+Inf, y) = +0 for y < 0\n\tPow(-Inf, y) = Pow(-0, -y)\n\tPow(x, y) = NaN for finite x < 0 and finite non-integer y", "1.0"))
+	mathNamespace.InternVar("pow10", procPow10,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("n"))),
+			"Pow10 returns 10**n, the base-10 exponential of n.\n\nSpecial cases are:\n\n\tPow10(n) =    0 for n < -323\n\tPow10(n) = +Inf for n > 308", "1.0"))
+	mathNamespace.InternVar("remainder", procRemainder,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"), MakeSymbol("y"))),
+			"Remainder returns the IEEE 754 floating-point remainder of x/y.\n\nSpecial cases are:\n\n\tRemainder(±Inf, y) = NaN\n\tRemainder(NaN, y) = NaN\n\tRemainder(x, 0) = NaN\n\tRemainder(x, ±Inf) = x\n\tRemainder(x, NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("round", procRound,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Round returns the nearest integer, rounding half away from zero.\n\nSpecial cases are:\n\n\tRound(±0) = ±0\n\tRound(±Inf) = ±Inf\n\tRound(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("round-to-even", procRoundToEven,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"RoundToEven returns the nearest integer, rounding ties to even.\n\nSpecial cases are:\n\n\tRoundToEven(±0) = ±0\n\tRoundToEven(±Inf) = ±Inf\n\tRoundToEven(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("signbit?", procSignbit,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Signbit reports whether x is negative or negative zero.", "1.0"))
+	mathNamespace.InternVar("sin", procSin,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Sin returns the sine of the radian argument x.\n\nSpecial cases are:\n\n\tSin(±0) = ±0\n\tSin(±Inf) = NaN\n\tSin(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("sincos", procSincos,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Sincos returns Sin(x), Cos(x).\n\nSpecial cases are:\n\n\tSincos(±0) = ±0, 1\n\tSincos(±Inf) = NaN, NaN\n\tSincos(NaN) = NaN, NaN", "1.0"))
+	mathNamespace.InternVar("sinh", procSinh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Sinh returns the hyperbolic sine of x.\n\nSpecial cases are:\n\n\tSinh(±0) = ±0\n\tSinh(±Inf) = ±Inf\n\tSinh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("sqrt", procSqrt,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Sqrt returns the square root of x.\n\nSpecial cases are:\n\n\tSqrt(+Inf) = +Inf\n\tSqrt(±0) = ±0\n\tSqrt(x < 0) = NaN\n\tSqrt(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("tan", procTan,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Tan returns the tangent of the radian argument x.\n\nSpecial cases are:\n\n\tTan(±0) = ±0\n\tTan(±Inf) = NaN\n\tTan(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("tanh", procTanh,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Tanh returns the hyperbolic tangent of x.\n\nSpecial cases are:\n\n\tTanh(±0) = ±0\n\tTanh(±Inf) = ±1\n\tTanh(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("trunc", procTrunc,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Trunc returns the integer value of x.\n\nSpecial cases are:\n\n\tTrunc(±0) = ±0\n\tTrunc(±Inf) = ±Inf\n\tTrunc(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("y0", procY0,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Y0 returns the order-zero Bessel function of the second kind.\n\nSpecial cases are:\n\n\tY0(+Inf) = 0\n\tY0(0) = -Inf\n\tY0(x < 0) = NaN\n\tY0(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("y1", procY1,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("x"))),
+			"Y1 returns the order-one Bessel function of the second kind.\n\nSpecial cases are:\n\n\tY1(+Inf) = 0\n\tY1(0) = -Inf\n\tY1(x < 0) = NaN\n\tY1(NaN) = NaN", "1.0"))
+	mathNamespace.InternVar("yn", procYn,
+		MakeMeta(
+			NewListFrom(NewVectorFrom(MakeSymbol("n"), MakeSymbol("x"))),
+			"Yn returns the order-n Bessel function of the second kind.\n\nSpecial cases are:\n\n\tYn(n, +Inf) = 0\n\tYn(n ≥ 0, 0) = -Inf\n\tYn(n < 0, 0) = +Inf if n is odd, -Inf if n is even\n\tYn(n, x < 0) = NaN\n\tYn(n, NaN) = NaN", "1.0"))
+}
@@ -43,17 +43,18 @@ var sh Proc = func(args []Object) Object {
 	if err = cmd.Start(); err != nil {
 		panic(RT.NewError(err.Error()))
 	}
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(stdoutReader)
-	stdoutString := buf.String()
-	buf = new(bytes.Buffer)
-	buf.ReadFrom(stderrReader)
-	stderrString := buf.String()
-	if err = cmd.Wait(); err != nil {
-		EmptyArrayMap().Assoc(MakeKeyword("success"), Bool{B: false})
-	}
+	var stdoutString, stderrString string
+	RunUnlocked(func() {
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(stdoutReader)
+		stdoutString = buf.String()
+		buf = new(bytes.Buffer)
+		buf.ReadFrom(stderrReader)
+		stderrString = buf.String()
+		err = cmd.Wait()
+	})
 	res := EmptyArrayMap()
-	res.Add(MakeKeyword("success"), Bool{B: true})
+	res.Add(MakeKeyword("success"), Bool{B: err == nil})
 	res.Add(MakeKeyword("out"), String{S: stdoutString})
 	res.Add(MakeKeyword("err"), String{S: stderrString})
 	return res
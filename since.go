@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type (
+	lineRange struct {
+		start int
+		end   int
+	}
+	// Filters linter output, letting through only the findings
+	// that point to the changed lines. Lines that don't start with
+	// a position (stacktraces, etc.) follow the fate of the finding
+	// they belong to.
+	changedLinesFilter struct {
+		out     io.Writer
+		changed map[string][]lineRange
+		buf     bytes.Buffer
+		keep    bool
+	}
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+var findingPosition = regexp.MustCompile(`^(.+?):(\d+):\d+: `)
+
+// Every line of untracked files counts as changed.
+var wholeFile = lineRange{start: 1, end: math.MaxInt32}
+
+func git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// Runs git diff against the merge base of HEAD and revision
+// (so that changes made on revision after the current branch
+// diverged from it are not included) and returns changed line ranges
+// keyed by absolute file path. Untracked files are considered
+// changed entirely.
+func changedLines(revision string, paths []string) (map[string][]lineRange, error) {
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root := strings.TrimSpace(top)
+	base, err := git("merge-base", "HEAD", revision)
+	if err != nil {
+		return nil, err
+	}
+	// Explicit prefixes override diff.noprefix and diff.mnemonicPrefix
+	// settings, which would change the file names parsed below.
+	args := append([]string{"diff", "--no-color", "--no-ext-diff", "--unified=0", "--src-prefix=a/", "--dst-prefix=b/", strings.TrimSpace(base), "--"}, paths...)
+	diff, err := git(args...)
+	if err != nil {
+		return nil, err
+	}
+	// --full-name makes paths relative to the top level directory, as in diff output.
+	args = append([]string{"ls-files", "--others", "--exclude-standard", "--full-name", "--"}, paths...)
+	untracked, err := git(args...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]lineRange)
+	for _, name := range strings.Split(untracked, "\n") {
+		if name != "" {
+			file := filepath.Join(root, name)
+			res[file] = append(res[file], wholeFile)
+		}
+	}
+	var file string
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			file = ""
+			if name := strings.TrimPrefix(line, "+++ "); name != "/dev/null" {
+				file = filepath.Join(root, strings.TrimPrefix(name, "b/"))
+			}
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count > 0 {
+				res[file] = append(res[file], lineRange{start: start, end: start + count - 1})
+			}
+		}
+	}
+	return res, nil
+}
+
+func newChangedLinesFilter(out io.Writer, changed map[string][]lineRange) *changedLinesFilter {
+	return &changedLinesFilter{
+		out:     out,
+		changed: changed,
+	}
+}
+
+func (f *changedLinesFilter) isChanged(filename string, line int) bool {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return false
+	}
+	for _, r := range f.changed[abs] {
+		if line >= r.start && line <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *changedLinesFilter) writeLine(line []byte) error {
+	if m := findingPosition.FindSubmatch(line); m != nil {
+		n, _ := strconv.Atoi(string(m[2]))
+		f.keep = f.isChanged(string(m[1]), n)
+	}
+	if f.keep {
+		_, err := f.out.Write(line)
+		return err
+	}
+	return nil
+}
+
+func (f *changedLinesFilter) Write(p []byte) (int, error) {
+	f.buf.Write(p)
+	for {
+		i := bytes.IndexByte(f.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := f.writeLine(f.buf.Next(i + 1)); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Writes the last line if it doesn't end with a newline.
+func (f *changedLinesFilter) Flush() error {
+	if f.buf.Len() == 0 {
+		return nil
+	}
+	return f.writeLine(f.buf.Next(f.buf.Len()))
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedLinesFilter(t *testing.T) {
+	var out bytes.Buffer
+	f := newChangedLinesFilter(&out, map[string][]lineRange{
+		"/src/a.clj": {{start: 2, end: 3}},
+	})
+	f.Write([]byte("/src/a.clj:1:1: Parse warning: unchanged\n/src/a.clj:2:1: Parse "))
+	f.Write([]byte("error: changed\n  stacktrace of changed\n/src/b.clj:2:1: Parse warning: other file\n"))
+	f.Write([]byte("  stacktrace of other file\n/src/a.clj:3:5: Parse warning: no newline"))
+	if err := f.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "/src/a.clj:2:1: Parse error: changed\n  stacktrace of changed\n/src/a.clj:3:5: Parse warning: no newline"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func runGit(t *testing.T, args ...string) {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) {
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir, err := ioutil.TempDir("", "joker-since")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Symlinks (e.g. /tmp on macOS) would make the paths reported
+	// by git differ from dir.
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, "init", "-q")
+	// File names in diff output must not depend on user's settings.
+	runGit(t, "config", "diff.mnemonicPrefix", "true")
+	runGit(t, "checkout", "-q", "-b", "main")
+	writeFile(t, "a.clj", "(ns a)\n(def x 1)\n(def y 2)\n")
+	runGit(t, "add", "a.clj")
+	runGit(t, "commit", "-q", "-m", "initial")
+	runGit(t, "checkout", "-q", "-b", "feature")
+	// Change made on main after the feature branch diverged from it
+	// must not be reported.
+	runGit(t, "checkout", "-q", "main")
+	writeFile(t, "a.clj", "(ns a)\n(def x 10)\n(def y 2)\n")
+	runGit(t, "commit", "-q", "-am", "change on main")
+	runGit(t, "checkout", "-q", "feature")
+	writeFile(t, "a.clj", "(ns a)\n(def x 1)\n(def y 20)\n")
+	writeFile(t, "b.clj", "(ns b)\n")
+
+	changed, err := changedLines("main", []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]lineRange{
+		filepath.Join(dir, "a.clj"): {{start: 3, end: 3}},
+		filepath.Join(dir, "b.clj"): {wholeFile},
+	}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+}
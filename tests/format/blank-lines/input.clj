@@ -0,0 +1,9 @@
+(def a 1)
+
+
+
+(def b 2)
+(defn c []
+
+
+  (+ a b))   
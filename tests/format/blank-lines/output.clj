@@ -0,0 +1,6 @@
+(def a 1)
+
+(def b 2)
+(defn c []
+
+  (+ a b))
@@ -0,0 +1,8 @@
+(def chars [\ 
+ 1])
+
+(def tab [\	
+  \space])
+
+(def s "trailing   
+   spaces")   
@@ -0,0 +1,7 @@
+(my-macro x
+y
+z)
+
+
+(when true
+  1)
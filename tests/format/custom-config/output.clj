@@ -0,0 +1,5 @@
+(my-macro x
+  y
+  z)
+(when true
+  1)
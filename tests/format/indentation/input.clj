@@ -0,0 +1,23 @@
+(ns foo.bar
+(:require [clojure.string :as s]))
+
+(defn f
+[x y]
+    (let [a (inc x)
+  b (dec y)]
+(if (pos? a)
+  (+ a
+b)
+        (s/join ","
+    [a b]))))
+
+(when-let [x (f 1 2)] (println x)
+(println "done"))
+
+(cond-> {}
+  true (assoc :a 1)
+)
+
+(foo 1
+2
+3)
@@ -0,0 +1,12 @@
+(ns builtin-ns.core
+  (:require [joker.string :as s]
+            [other.lib :as lib]))
+
+(joker.string/spilt "a b" #" ")
+(joker.string/split "a b" #" ")
+(s/trim-spaec " a ")
+(s/join "," [1 2])
+(joker.os/shh "ls")
+(lib/anything 1)
+(var joker.string/rplace)
+(joker.string/spilt "a b" #" ")
@@ -1,12 +1,14 @@
 ;; Should PASS
 #?(:clj 1)
 #?@(:cljs 3)
+[1 #?@(:clj [2 3]) #?(:cljs 4)]
+(defn f [x] #?@(:clj [(println x) x]))
 (def regexp #?(:clj re-pattern :cljs js/XRegExp))
 
 
 ;; Should FAIL
 #?(:cljs)
 #?(:cljs (let [] 1) :default (let [] 1))
-#?@(:cljs 3 :clj (let [a 1]))
+#?@(:cljs 3 :clj [(let [a 1])])
 #?(:clj 234ewr :cljs 2)
 
@@ -0,0 +1,4 @@
+(ns foo)
+
+(def p #my/point [1 2])
+(def q #other/tag [1 2])
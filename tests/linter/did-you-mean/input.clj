@@ -0,0 +1,10 @@
+(ns foo
+  (:require [clojure.string :as string]))
+
+(defn process [counter]
+  (inc countr))
+
+(mapp inc [1 2])
+(strng/join [])
+(prn ::strin/a)
+(proces 1)
@@ -0,0 +1,13 @@
+/**
+ * @constructor
+ * This comment line mentions a.notMethod = 1 and is ignored.
+ */
+function Widget() {}
+
+/** @param {string} title */
+Widget.prototype.setTitle = function(title) {};
+
+Widget.prototype.render;
+
+var widgets = {};
+widgets.registerWidget = function(w) {};
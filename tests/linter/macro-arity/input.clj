@@ -0,0 +1,10 @@
+(when)
+(when true)
+(->)
+(-> 1)
+(-> 1 inc)
+(if-let)
+(defn)
+(let)
+(let [])
+(when-not)
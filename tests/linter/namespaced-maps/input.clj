@@ -0,0 +1,7 @@
+(ns foo
+  (:require [clojure.string :as s]))
+
+(def person #:person{:name "Ann" :_/id 1})
+(def current #::{:a 1})
+(def aliased #::s{:b 2})
+(def unknown #::zz{:c 3})
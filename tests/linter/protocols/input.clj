@@ -0,0 +1,28 @@
+(ns tests.protocols)
+
+(defprotocol Shape
+  "Shapes"
+  (area [this] "Area")
+  (scale [this k] [this k j]))
+
+(extend-protocol Shape
+  String
+  (area [s] (count s))
+  (scale ([s k] (apply str (repeat k s)))
+    ([s k j] (* k j)))
+  nil
+  (area [_] 0))
+
+(extend-type clojure.lang.IPersistentVector
+  Shape
+  (area [v] (reduce + v)))
+
+(area "abc")
+(scale "abc" 2)
+
+(reify Shape
+  (area [this] (perimeter this)))
+
+(extend-type Object
+  Shape
+  (area [o] u1))
@@ -0,0 +1,12 @@
+(ns foo)
+
+(defn a [x]
+  (inc x)
+
+(defn b [y]
+  (inc y]))
+
+(defn c [z]
+  z)
+
+(defn d [] (foo
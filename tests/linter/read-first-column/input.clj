@@ -0,0 +1,14 @@
+(ns foo)
+
+(comment
+(+ 1 2)
+)
+
+(def v [1
+(inc 2)])
+
+(defn f [x]
+(let [y (inc x)]
+(* y 2)))
+
+(f (first v))
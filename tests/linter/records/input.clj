@@ -0,0 +1,30 @@
+(ns records.core)
+
+(defprotocol Shape
+  (area [s])
+  (perimeter [s]))
+
+(defrecord Rect [w h]
+  Shape
+  (area [this] (* w h))
+  (perimeter [_] (* 2 (+ w h))))
+
+(deftype Circle [r]
+  Shape
+  (area [_] (* 3.14 r r))
+  (perimeter [_] (* 2 3.14 rr)))
+
+(area (->Rect 1 2))
+(area (map->Rect {:w 1 :h 2}))
+(perimeter (->Circle 1))
+(map->Circle {:r 1})
+(:w (->Rect 1 2))
+(instance? Rect (->Rect 1 2))
+(record? (->Rect 1 2))
+(->Square 1)
+
+(defrecord Named [n]
+  Object
+  (toString [_] (str n))
+  (finalize [_] nn))
+(str (->Named "x"))
@@ -0,0 +1,50 @@
+;; Should PASS
+
+(loop [x 1]
+  (if (< x 10)
+    (recur (inc x))
+    x))
+
+(defn f1 [x]
+  (let [y (dec x)]
+    (when (pos? y)
+      (recur y))))
+
+(loop [x 1]
+  (fn [] (recur))
+  (when (< x 10)
+    (recur (inc x))))
+
+(defn f3 [x]
+  (letfn [(g [n] (if (pos? n) (recur (dec n)) n))]
+    (g x)))
+
+(defmulti m :k)
+(defmethod m :a [x n]
+  (if (pos? n) (recur x (dec n)) n))
+
+(loop [x 1]
+  (try
+    (loop [y x]
+      (if (pos? y) (recur (dec y)) y))
+    (finally
+      (println x))))
+
+;; Should FAIL
+(loop [x 1]
+  (let [y (recur 2)]
+    y))
+
+(defn f2 [x]
+  (if (recur x)
+    1
+    2))
+
+(loop [x 1]
+  (inc (recur x)))
+
+(loop [x 1]
+  (try
+    (recur x)
+    (catch Exception e
+      x)))
@@ -0,0 +1,2 @@
+(def limits [##Inf ##-Inf ##NaN])
+(def bad ##Infinity)
@@ -0,0 +1,29 @@
+;; Should PASS
+
+(defn f1 [x]
+  (when-not x
+    (throw (ex-info "x is required" {})))
+  x)
+
+(defn f2 [x]
+  (if x
+    (throw (ex-info "x" {}))
+    x))
+
+;; Should FAIL
+(defn f3 []
+  (throw (ex-info "boom" {}))
+  (println "never printed"))
+
+(defn f4 [x]
+  (let [y (inc x)]
+    (if y
+      (throw (ex-info "a" {}))
+      (throw (ex-info "b" {})))
+    y))
+
+(try
+  (throw (ex-info "c" {}))
+  1
+  (catch Exception e
+    (do (throw e) 2)))
@@ -0,0 +1,14 @@
+(ns metrics.input)
+
+(defn simple [x]
+  (inc x))
+
+(defn branchy
+  ([x] (branchy x 0))
+  ([x y]
+   (cond
+     (pos? x) (if (pos? y) :both :x)
+     (neg? x) (when y :neg)
+     :else nil)))
+
+(def not-a-fn 1)